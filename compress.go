@@ -0,0 +1,112 @@
+package swagger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"crypto/sha256"
+	"strconv"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// defaultCompressionMinSize is the default minimum size of a response body sent gzip-encoded.
+const defaultCompressionMinSize = 1024
+
+// pageGzipCacheSize is the number of gzip-encoded page variants cached per handler.
+const pageGzipCacheSize = 16
+
+// acceptsGzip reports whether the client accepts a gzip-encoded response.
+// A request without Accept-Encoding is answered uncompressed.
+func acceptsGzip(c fiber.Ctx) bool {
+	return c.Get(fiber.HeaderAcceptEncoding) != "" && c.AcceptsEncodings("gzip") == "gzip"
+}
+
+// gzipBytes returns the gzip encoding of body.
+func gzipBytes(body []byte) []byte {
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	// Writes to a bytes.Buffer cannot fail.
+	_, _ = zw.Write(body)
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+// shouldGzip reports whether a response body of the given size is sent
+// gzip-encoded, i.e. the client accepts it and the body is at least
+// Config.CompressionMinSize bytes.
+func shouldGzip(c fiber.Ctx, cfg Config, size int) bool {
+	return !cfg.DisableBuiltinCompression && size >= cfg.CompressionMinSize && acceptsGzip(c)
+}
+
+// varyEncoding sets Vary: Accept-Encoding on responses the handler may compress,
+// so shared caches keep the encodings apart.
+func varyEncoding(c fiber.Ctx, cfg Config) {
+	if !cfg.DisableBuiltinCompression {
+		c.Vary(fiber.HeaderAcceptEncoding)
+	}
+}
+
+// sendNegotiated writes body, gzip-encoded when compress is set. gzipped returns
+// the encoded body, letting callers compress cached bodies once.
+func sendNegotiated(c fiber.Ctx, compress bool, body []byte, gzipped func() []byte) error {
+	if compress {
+		body = gzipped()
+		c.Set(fiber.HeaderContentEncoding, "gzip")
+	}
+	c.Set(fiber.HeaderContentLength, strconv.Itoa(len(body)))
+	return c.Send(body)
+}
+
+// gzipCache caches the gzip encoding of rendered pages by their content, so a page
+// is compressed once even though it is rendered on every request. Once it holds
+// more than size pages, the least recently used one is evicted.
+type gzipCache struct {
+	mu    sync.Mutex
+	size  int
+	pages map[[sha256.Size]byte]*list.Element
+	order *list.List
+}
+
+// gzipEntry is a cached gzip encoding together with its key, kept in gzipCache.order.
+type gzipEntry struct {
+	key     [sha256.Size]byte
+	gzipped []byte
+}
+
+func newGzipCache(size int) *gzipCache {
+	return &gzipCache{
+		size:  size,
+		pages: make(map[[sha256.Size]byte]*list.Element),
+		order: list.New(),
+	}
+}
+
+// gzip returns the gzip encoding of body, compressing it only if it is not cached.
+func (g *gzipCache) gzip(body []byte) []byte {
+	key := sha256.Sum256(body)
+
+	g.mu.Lock()
+	if elem, ok := g.pages[key]; ok {
+		g.order.MoveToFront(elem)
+		g.mu.Unlock()
+		return elem.Value.(*gzipEntry).gzipped
+	}
+	g.mu.Unlock()
+
+	// Compress outside the lock; concurrent misses for the same page are harmless.
+	gzipped := gzipBytes(body)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.pages[key]; !ok {
+		g.pages[key] = g.order.PushFront(&gzipEntry{key: key, gzipped: gzipped})
+		for g.order.Len() > g.size {
+			oldest := g.order.Back()
+			g.order.Remove(oldest)
+			delete(g.pages, oldest.Value.(*gzipEntry).key)
+		}
+	}
+	return gzipped
+}
@@ -1,7 +1,13 @@
 package swagger
 
 import (
+	"fmt"
 	"html/template"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
 )
 
 // Config stores SwaggerUI configuration variables
@@ -10,10 +16,56 @@ type Config struct {
 	// default: ""
 	InstanceName string `json:"-"`
 
+	// Path of a file to serve the spec document from instead of the registered swag instance.
+	// default: ""
+	FilePath string `json:"-"`
+
+	// URL of a spec document served by another service. It is fetched server-side and cached,
+	// so the browser only talks to this origin. Takes precedence over FilePath.
+	// default: ""
+	RemoteSpecURL string `json:"-"`
+
+	// Duration a spec document fetched from RemoteSpecURL is cached before it is fetched again.
+	// default: 5m
+	RemoteSpecTTL time.Duration `json:"-"`
+
+	// Spec document served when RemoteSpecURL cannot be fetched and no previous copy is cached.
+	// Without it, such requests fail with 502 Bad Gateway.
+	// default: ""
+	RemoteSpecFallback string `json:"-"`
+
+	// Maximum size in bytes of a spec document read from FilePath or RemoteSpecURL. Larger documents are
+	// answered with 413 Request Entity Too Large. Set a negative value to disable the limit.
+	// default: 32MB
+	SpecSizeLimit int64 `json:"-"`
+
+	// Maximum duration to wait for the spec document to be read from FilePath or RemoteSpecURL.
+	// Requests exceeding it fail with 504 Gateway Timeout. Zero disables the timeout for FilePath,
+	// while RemoteSpecURL fetches are then bounded by 30 seconds. It has no effect on swag documents
+	// which are read from memory.
+	// default: 0
+	SpecReadTimeout time.Duration `json:"-"`
+
+	// Name of a route parameter whose value selects the swag instance used to serve the spec, e.g. "name"
+	// for routes like "/services/:name/docs/*". Takes precedence over InstanceNameFromCookie and falls back
+	// to InstanceName when the value names an unregistered instance.
+	// default: ""
+	InstanceNameParam string `json:"-"`
+
+	// Name of a cookie whose value selects the swag instance used to serve the spec.
+	// Falls back to InstanceName when the cookie is missing or names an unregistered instance.
+	// default: ""
+	InstanceNameFromCookie string `json:"-"`
+
 	// Title pointing to title of HTML page.
 	// default: "Swagger UI"
 	Title string `json:"-"`
 
+	// Version of swagger-ui loaded from the CDN. Must be a semantic version such as "5.11.0",
+	// which allows pinning a known-good, audited release.
+	// default: "4.1.3"
+	SwaggerUIVersion string `json:"-"`
+
 	// URL to fetch external configuration document from.
 	// default: ""
 	ConfigURL string `json:"configUrl,omitempty"`
@@ -22,6 +74,19 @@ type Config struct {
 	// default: "doc.json"
 	URL string `json:"url,omitempty"`
 
+	// Multiple API definitions listed in a dropdown of the top bar. Swagger UI ignores URL when it is set.
+	// default: nil
+	URLs []SpecURL `json:"urls,omitempty"`
+
+	// Query parameters appended to URL and to every URLs entry, e.g. an access token for a spec endpoint
+	// protected with SpecAuth. Values are URL-escaped. Set it from BeforeRender to vary it per request.
+	// default: nil
+	SpecURLQuery map[string]string `json:"-"`
+
+	// Name of the URLs entry selected when the page opens. Must match the name of one of URLs.
+	// default: the name of the first URLs entry
+	UrlsPrimaryName string `json:"urls.primaryName,omitempty"`
+
 	// Enables overriding configuration parameters via URL search params.
 	// default: false
 	QueryConfigEnabled bool `json:"queryConfigEnabled,omitempty"`
@@ -30,6 +95,11 @@ type Config struct {
 	// default: "StandaloneLayout"
 	Layout string `json:"layout,omitempty"`
 
+	// Hides the spec URL input and Explore button of the StandaloneLayout top bar, keeping the logo and
+	// the URLs dropdown, so users cannot point the page at another document. To hide the whole top bar use a different Layout.
+	// default: false
+	HideExploreBar bool `json:"-"`
+
 	// An array of plugin functions to use in Swagger UI.
 	// default: [SwaggerUIBundle.plugins.DownloadUrl]
 	Plugins []template.JS `json:"-"`
@@ -108,7 +178,7 @@ type Config struct {
 	RequestSnippetsEnabled bool `json:"requestSnippetsEnabled,omitempty"`
 
 	// OAuth redirect URL.
-	// default: ""
+	// default: the "oauth2-redirect.html" page served next to the index page
 	OAuth2RedirectUrl string `json:"oauth2RedirectUrl,omitempty"`
 
 	// MUST be a function. Function to intercept remote definition, "Try it out", and OAuth 2.0 requests.
@@ -126,6 +196,12 @@ type Config struct {
 	// default: ""
 	ResponseInterceptor template.JS `json:"-"`
 
+	// If set to true, failed "Try it out" responses show their status code and the start of the response body
+	// as the error message. It runs after ResponseInterceptor, on the response that returns. Network errors
+	// never reach the interceptors and keep the default message.
+	// default: false
+	FriendlyTryItErrors bool `json:"-"`
+
 	// If set to true, uses the mutated request returned from a requestInterceptor to produce the curl command in the UI,
 	// otherwise the request before the requestInterceptor was applied is used.
 	// default: true
@@ -183,6 +259,231 @@ type Config struct {
 	// Applies custom JavaScript scripts.
 	// default ""
 	CustomScript template.JS `json:"-"`
+
+	// JavaScript run inside window.onload right before SwaggerUIBundle is initialized, after the config
+	// object is built, e.g. to set globals from the host app that interceptors read. Unlike CustomScript,
+	// which runs in <head> before the page loads, it can also adjust the `config` variable.
+	// default: ""
+	PreInitScript template.JS `json:"-"`
+
+	// Interval at which the UI re-fetches the spec document and re-renders when its ETag changed,
+	// e.g. while developing against a regenerating spec. Responses without an ETag, e.g. with
+	// DisableBuiltinETag, are compared by content instead. Zero disables auto-refresh.
+	// default: 0
+	AutoRefreshInterval time.Duration `json:"-"`
+
+	// URL of the page favicon, a shortcut for a single Favicons entry.
+	// default: the Swagger UI favicons
+	FaviconURL string `json:"-"`
+
+	// Icon links rendered in the <head> of the page, e.g. several sizes and an apple-touch-icon.
+	// Takes precedence over FaviconURL.
+	// default: nil
+	Favicons []FaviconLink `json:"-"`
+
+	// HTML fragment inserted into <head>, e.g. meta tags for link previews.
+	// It is rendered unescaped, so it must come from a trusted source.
+	// default: ""
+	HeadHTML template.HTML `json:"-"`
+
+	// HTML fragment inserted at the start of <body>.
+	// It is rendered unescaped, so it must come from a trusted source.
+	// default: ""
+	BodyHTML template.HTML `json:"-"`
+
+	// Removes the fields at the given dotted paths from the spec document, e.g. "info.contact" or
+	// "paths.*.*.description". A "*" segment matches any single object key or array index, and a "**"
+	// segment matches any number of levels, so "**.example" removes every example field.
+	// Applied once before the document is cached, ahead of Sanitizer, InfoOverride and NormalizeSpec.
+	// default: nil
+	RedactPaths []string `json:"-"`
+
+	// Sanitizer rewrites the spec document once before it is cached, e.g. to mask secrets in example
+	// values. It runs after RedactPaths and before InfoOverride and NormalizeSpec, and an error fails
+	// the request like a read error.
+	// default: nil
+	Sanitizer func(doc []byte) ([]byte, error) `json:"-"`
+
+	// Minimum size in bytes of a spec document or page served gzip-encoded. Smaller bodies are sent
+	// uncompressed regardless of Accept-Encoding, since compressing them costs more than it saves.
+	// A negative value compresses every body.
+	// default: 1024
+	CompressionMinSize int `json:"-"`
+
+	// Turns off the gzip encoding of spec documents and pages, together with their Vary: Accept-Encoding
+	// header, for apps running Fiber's compress middleware in front of the handler. Leaving both enabled
+	// is harmless since the middleware skips encoded responses, but wastes work.
+	// default: false
+	DisableBuiltinCompression bool `json:"-"`
+
+	// Turns off the ETag of the spec document and the If-None-Match handling based on it, for apps running
+	// Fiber's etag middleware. Last-Modified and If-Modified-Since keep working for file sources.
+	// default: false
+	DisableBuiltinETag bool `json:"-"`
+
+	// Maximum number of spec document variants cached by the handler, e.g. one per swag instance and
+	// InfoOverride. The least recently used variant is evicted beyond it. See also ClearCache.
+	// default: 64
+	SpecCacheSize int `json:"-"`
+
+	// Re-encodes the spec document once with sorted object keys, so unchanged content is served byte-for-byte
+	// identical across rebuilds. Array order is preserved. Applied before the document is cached.
+	// default: false
+	NormalizeSpec bool `json:"-"`
+
+	// Indents the served spec document with the given whitespace, e.g. "  " or "\t", so it can be checked
+	// in with the repository's conventions. Applied once after the other transforms, before the document
+	// is cached. Only JSON documents can be indented.
+	// default: ""
+	DocJSONIndent string `json:"-"`
+
+	// Overrides fields of the info object of the served spec document, e.g. for white-label deployments.
+	// Empty fields are left untouched. Set it from BeforeRender together with InlineSpec, or use
+	// SpecTransformPerRequest, to vary the values per request.
+	// default: nil
+	InfoOverride *InfoOverride `json:"-"`
+
+	// SpecTransformPerRequest rewrites the spec document on every request, e.g. to set the servers from the
+	// caller's tenant. It receives a copy of the cached document, so it cannot affect other requests.
+	// default: nil
+	SpecTransformPerRequest func(c fiber.Ctx, doc []byte) ([]byte, error) `json:"-"`
+
+	// Serves the spec document as JSONP when the request has a "callback" query parameter,
+	// for legacy cross-domain consumers. Callback names must be plain or dotted JavaScript
+	// identifiers, other names are rejected with 400 Bad Request. Documents that are not JSON,
+	// e.g. a YAML FilePath, are not wrapped and such requests fail with 406 Not Acceptable.
+	// default: false
+	AllowJSONP bool `json:"-"`
+
+	// SpecModTimeFunc reports when the spec document was last regenerated.
+	// It is used to set the Last-Modified header and to reload the cached document once it changes.
+	// When nil, swag documents are read on every request and only validated with a content-hash ETag.
+	// default: nil
+	SpecModTimeFunc func() time.Time `json:"-"`
+
+	// Name of a response header of the spec endpoint carrying the info.version of the document,
+	// e.g. "X-API-Version", so clients can read the API version with a HEAD request when the handler is
+	// also registered for HEAD. The version is parsed once per cached document. Omitted when the document has none.
+	// default: ""
+	SpecVersionHeader string `json:"-"`
+
+	// Additional response headers set on spec responses, e.g. "Vary" or "Surrogate-Control".
+	// They are applied after the built-in caching headers, but cannot override Content-Encoding,
+	// Content-Length, Content-Range, Content-Type or Transfer-Encoding.
+	// default: nil
+	SpecHeaders map[string]string `json:"-"`
+
+	// SpecAuth authorizes requests for the spec document only, the UI pages stay public.
+	// Requests for which it returns false are rejected with 401 Unauthorized.
+	// default: nil
+	SpecAuth func(c fiber.Ctx) bool `json:"-"`
+
+	// Path prepended to the spec URL and redirect locations, for gateways that strip a prefix before
+	// forwarding, e.g. "/public" when "/public/docs" reaches the app as "/docs". When set, it replaces
+	// the X-Forwarded-Prefix header, so the external links do not depend on the request.
+	// default: ""
+	ExternalPrefix string `json:"-"`
+
+	// If set to true, the UI is served directly at the prefix with a trailing slash (e.g. "/docs/")
+	// and requests for the prefix without it (e.g. "/docs") are redirected there.
+	// Otherwise both forms are redirected to "index.html".
+	// default: false
+	RedirectTrailingSlash bool `json:"-"`
+
+	// Handler invoked for unknown docs sub-paths, e.g. to render a branded page or redirect to the index.
+	// default: nil -> responds with 404 Not Found
+	NotFoundHandler fiber.Handler `json:"-"`
+
+	// Echoes the incoming "X-Request-ID" header on every docs response, generating a UUID when it is absent.
+	// default: false
+	EchoRequestID bool `json:"-"`
+
+	// OnReady is called exactly once, after the spec document was successfully loaded for the first time.
+	// default: nil
+	OnReady func(cfg Config) `json:"-"`
+
+	// Embeds the spec document into the Swagger UI page instead of letting the UI fetch it from URL,
+	// saving a round-trip on first paint. The "doc.json" route is still served. The document is only
+	// embedded for requests SpecAuth allows and when it is JSON; otherwise the UI fetches it from URL.
+	// default: false
+	InlineSpec bool `json:"-"`
+
+	// Collapses the rendered index HTML by stripping comments and collapsing runs of whitespace to a single space.
+	// Tags, including their attribute values, and the content of inline scripts and styles are left untouched.
+	// default: false
+	MinifyHTML bool `json:"-"`
+
+	// Localized titles and descriptions keyed by language tag, e.g. "en" or "de-AT". The entry best matching
+	// the request's Accept-Language header sets the page title, falling back to DefaultLocale.
+	// default: nil
+	Localized map[string]LocalizedInfo `json:"-"`
+
+	// Key of Localized used when no entry matches the request's Accept-Language header.
+	// default: ""
+	DefaultLocale string `json:"-"`
+
+	// If set to true, the selected Localized entry also overrides the title and description of the info
+	// object of the served spec document, on top of InfoOverride.
+	// default: false
+	LocalizeSpecInfo bool `json:"-"`
+
+	// If set to true, each component schema of the spec document is also served on its own at
+	// "components/schemas/{name}", for tools resolving $refs lazily. Swagger 2.0 documents serve their
+	// definitions instead. The schemas are read from the cached document; unknown names return 404.
+	// default: false
+	ServeComponents bool `json:"-"`
+
+	// Path below the prefix serving the entries of the paths object of the spec document as
+	// newline-delimited JSON, e.g. "paths.ndjson", one {"path":...,"item":...} object per line in
+	// document order. Encoded once per cached document. Empty disables the endpoint.
+	// default: ""
+	NDJSONPathsEndpoint string `json:"-"`
+
+	// If set to true, the Swagger UI page only carries the settings that are code (plugins, presets,
+	// interceptors, ...) and loads the others from "swagger-config.json" next to it, through the configUrl
+	// option. The page bytes then stay the same across requests and can be cached aggressively, while
+	// the settings, including those changed by BeforeRender, are generated per request.
+	// default: false
+	ExternalizeConfig bool `json:"-"`
+
+	// BeforeRender is called with a per-request copy of the config right before the index page is rendered.
+	// It can be used to tweak a few fields (e.g. Title or SyntaxHighlight.Theme) based on the request
+	// without affecting the config shared by other requests.
+	// default: nil
+	BeforeRender func(c fiber.Ctx, cfg *Config) `json:"-"`
+
+	// now is the time source of the remote spec TTL, so tests can advance it without sleeping.
+	// default: time.Now
+	now func() time.Time
+}
+
+// SpecURL is an entry of the API definitions dropdown.
+type SpecURL struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// InfoOverride holds the fields replaced in the info object of the spec document.
+type InfoOverride struct {
+	Title          string `json:"title,omitempty"`
+	Version        string `json:"version,omitempty"`
+	Description    string `json:"description,omitempty"`
+	TermsOfService string `json:"termsOfService,omitempty"`
+}
+
+// FaviconLink is an icon <link> of the page, see Config.Favicons.
+type FaviconLink struct {
+	// Link relation, e.g. "apple-touch-icon". Defaults to "icon".
+	Rel   string
+	Sizes string
+	Type  string
+	Href  string
+}
+
+// LocalizedInfo is the title and description shown for one language, see Config.Localized.
+type LocalizedInfo struct {
+	Title       string
+	Description string
 }
 
 type FilterConfig struct {
@@ -255,8 +556,9 @@ type OAuthConfig struct {
 
 var (
 	ConfigDefault = Config{
-		Title:  "Swagger UI",
-		Layout: "StandaloneLayout",
+		Title:            "Swagger UI",
+		SwaggerUIVersion: "4.1.3",
+		Layout:           "StandaloneLayout",
 		Plugins: []template.JS{
 			template.JS("SwaggerUIBundle.plugins.DownloadUrl"),
 		},
@@ -274,9 +576,96 @@ var (
 			Theme:    "agate",
 		},
 		ShowMutatedRequest: true,
+		SpecSizeLimit:      defaultSpecSizeLimit,
+		RemoteSpecTTL:      defaultRemoteSpecTTL,
+		SpecCacheSize:      defaultSpecCacheSize,
+		CompressionMinSize: defaultCompressionMinSize,
+		now:                time.Now,
 	}
 )
 
+// hasSpecURL reports whether urls contains an entry with the given name.
+func hasSpecURL(urls []SpecURL, name string) bool {
+	for _, u := range urls {
+		if u.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// semverPattern matches the semantic versions accepted for Config.SwaggerUIVersion.
+var semverPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+// clone returns a copy of the config that can be modified without affecting the receiver.
+func (cfg Config) clone() Config {
+	if cfg.URLs != nil {
+		cfg.URLs = append([]SpecURL(nil), cfg.URLs...)
+	}
+	if cfg.Plugins != nil {
+		cfg.Plugins = append([]template.JS(nil), cfg.Plugins...)
+	}
+	if cfg.Presets != nil {
+		cfg.Presets = append([]template.JS(nil), cfg.Presets...)
+	}
+	if cfg.RequestCurlOptions != nil {
+		cfg.RequestCurlOptions = append([]string(nil), cfg.RequestCurlOptions...)
+	}
+	if cfg.SupportedSubmitMethods != nil {
+		cfg.SupportedSubmitMethods = append([]string(nil), cfg.SupportedSubmitMethods...)
+	}
+	if cfg.SpecURLQuery != nil {
+		query := make(map[string]string, len(cfg.SpecURLQuery))
+		for key, value := range cfg.SpecURLQuery {
+			query[key] = value
+		}
+		cfg.SpecURLQuery = query
+	}
+	if cfg.SpecHeaders != nil {
+		headers := make(map[string]string, len(cfg.SpecHeaders))
+		for key, value := range cfg.SpecHeaders {
+			headers[key] = value
+		}
+		cfg.SpecHeaders = headers
+	}
+	if cfg.Localized != nil {
+		localized := make(map[string]LocalizedInfo, len(cfg.Localized))
+		for key, info := range cfg.Localized {
+			localized[key] = info
+		}
+		cfg.Localized = localized
+	}
+	if cfg.Favicons != nil {
+		cfg.Favicons = append([]FaviconLink(nil), cfg.Favicons...)
+	}
+	if cfg.RedactPaths != nil {
+		cfg.RedactPaths = append([]string(nil), cfg.RedactPaths...)
+	}
+	if cfg.SyntaxHighlight != nil {
+		syntaxHighlight := *cfg.SyntaxHighlight
+		cfg.SyntaxHighlight = &syntaxHighlight
+	}
+	if cfg.InfoOverride != nil {
+		infoOverride := *cfg.InfoOverride
+		cfg.InfoOverride = &infoOverride
+	}
+	if cfg.OAuth != nil {
+		oauth := *cfg.OAuth
+		if oauth.Scopes != nil {
+			oauth.Scopes = append([]string(nil), oauth.Scopes...)
+		}
+		if oauth.AdditionalQueryStringParams != nil {
+			params := make(map[string]string, len(oauth.AdditionalQueryStringParams))
+			for k, v := range oauth.AdditionalQueryStringParams {
+				params[k] = v
+			}
+			oauth.AdditionalQueryStringParams = params
+		}
+		cfg.OAuth = &oauth
+	}
+	return cfg
+}
+
 // Helper function to set default values
 func configDefault(config ...Config) Config {
 	// Return default config if nothing provided
@@ -291,10 +680,30 @@ func configDefault(config ...Config) Config {
 		cfg.Title = ConfigDefault.Title
 	}
 
+	if cfg.SwaggerUIVersion == "" {
+		cfg.SwaggerUIVersion = ConfigDefault.SwaggerUIVersion
+	}
+	if !semverPattern.MatchString(cfg.SwaggerUIVersion) {
+		panic(Error{Category: ErrConfig, Err: fmt.Errorf("invalid SwaggerUIVersion %q", cfg.SwaggerUIVersion)})
+	}
+
+	if strings.Trim(cfg.DocJSONIndent, " \t") != "" {
+		panic(Error{Category: ErrConfig, Err: fmt.Errorf("DocJSONIndent %q must only contain spaces and tabs", cfg.DocJSONIndent)})
+	}
+
 	if cfg.Layout == "" {
 		cfg.Layout = ConfigDefault.Layout
 	}
 
+	if len(cfg.URLs) > 0 {
+		if cfg.UrlsPrimaryName == "" {
+			cfg.UrlsPrimaryName = cfg.URLs[0].Name
+		}
+		if !hasSpecURL(cfg.URLs, cfg.UrlsPrimaryName) {
+			panic(Error{Category: ErrConfig, Err: fmt.Errorf("UrlsPrimaryName %q does not match any of URLs", cfg.UrlsPrimaryName)})
+		}
+	}
+
 	if cfg.DefaultModelRendering == "" {
 		cfg.DefaultModelRendering = ConfigDefault.DefaultModelRendering
 	}
@@ -315,5 +724,25 @@ func configDefault(config ...Config) Config {
 		cfg.SyntaxHighlight = ConfigDefault.SyntaxHighlight
 	}
 
+	if cfg.SpecSizeLimit == 0 {
+		cfg.SpecSizeLimit = ConfigDefault.SpecSizeLimit
+	}
+
+	if cfg.RemoteSpecTTL == 0 {
+		cfg.RemoteSpecTTL = ConfigDefault.RemoteSpecTTL
+	}
+
+	if cfg.now == nil {
+		cfg.now = time.Now
+	}
+
+	if cfg.SpecCacheSize <= 0 {
+		cfg.SpecCacheSize = ConfigDefault.SpecCacheSize
+	}
+
+	if cfg.CompressionMinSize == 0 {
+		cfg.CompressionMinSize = ConfigDefault.CompressionMinSize
+	}
+
 	return cfg
 }
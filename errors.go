@@ -0,0 +1,52 @@
+package swagger
+
+// ErrorCategory classifies the failures reported by the swagger middleware.
+type ErrorCategory int
+
+const (
+	// ErrTemplate reports a failure to parse or render a page template.
+	ErrTemplate ErrorCategory = iota + 1
+
+	// ErrSpecRead reports a failure to read, fetch or transform the spec document.
+	ErrSpecRead
+
+	// ErrConfig reports an invalid configuration.
+	ErrConfig
+)
+
+// String returns the name of the category.
+func (ec ErrorCategory) String() string {
+	switch ec {
+	case ErrTemplate:
+		return "template"
+	case ErrSpecRead:
+		return "spec read"
+	case ErrConfig:
+		return "config"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is the error returned by the swagger handlers. It carries the category
+// of the failure, so an application-level fiber.ErrorHandler can branch on it:
+//
+//	var swaggerErr swagger.Error
+//	if errors.As(err, &swaggerErr) && swaggerErr.Category == swagger.ErrSpecRead {
+//		return c.SendStatus(fiber.StatusServiceUnavailable)
+//	}
+//
+// The cause is available through errors.Unwrap, so a wrapped *fiber.Error
+// still determines the status code used by the default error handler.
+type Error struct {
+	Category ErrorCategory
+	Err      error
+}
+
+func (e Error) Error() string {
+	return "fiber: swagger middleware error -> " + e.Category.String() + ": " + e.Err.Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
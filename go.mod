@@ -6,6 +6,7 @@ toolchain go1.23.0
 
 require (
 	github.com/gofiber/fiber/v3 v3.0.0-beta.4
+	github.com/google/uuid v1.6.0
 	github.com/swaggo/swag v1.16.4
 )
 
@@ -21,7 +22,6 @@ require (
 	github.com/go-openapi/swag v0.19.15 // indirect
 	github.com/gofiber/schema v1.2.0 // indirect
 	github.com/gofiber/utils/v2 v2.0.0-beta.7 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/klauspost/compress v1.17.11 // indirect
 	github.com/mailru/easyjson v0.7.6 // indirect
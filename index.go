@@ -9,9 +9,25 @@ const indexTmpl string = `
     <meta charset="UTF-8">
     <title>{{.Title}}</title>
     <link href="https://fonts.googleapis.com/css?family=Open+Sans:400,700|Source+Code+Pro:300,600|Titillium+Web:400,600,700" rel="stylesheet">
-    <link rel="stylesheet" type="text/css" href="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/4.1.3/swagger-ui.css">
-    <link rel="icon" type="image/png" href="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/4.1.3/favicon-32x32.png" sizes="32x32" />
-    <link rel="icon" type="image/png" href="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/4.1.3/favicon-16x16.png" sizes="16x16" />
+    <link rel="stylesheet" type="text/css" href="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/{{.SwaggerUIVersion}}/swagger-ui.css">
+    {{- if .Favicons}}
+    {{- range .Favicons}}
+    <link rel="{{with .Rel}}{{.}}{{else}}icon{{end}}"{{with .Type}} type="{{.}}"{{end}} href="{{.Href}}"{{with .Sizes}} sizes="{{.}}"{{end}} />
+    {{- end}}
+    {{- else if .FaviconURL}}
+    <link rel="icon" href="{{.FaviconURL}}" />
+    {{- else}}
+    <link rel="icon" type="image/png" href="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/{{.SwaggerUIVersion}}/favicon-32x32.png" sizes="32x32" />
+    <link rel="icon" type="image/png" href="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/{{.SwaggerUIVersion}}/favicon-16x16.png" sizes="16x16" />
+    {{- end}}
+    {{- if .HeadHTML}}
+    {{.HeadHTML}}
+    {{- end}}
+    {{- if .HideExploreBar}}
+      <style>
+        .swagger-ui .topbar .download-url-input, .swagger-ui .topbar .download-url-button { display: none !important; }
+      </style>
+    {{- end}}
     {{- if .CustomStyle}}
       <style>
         body { margin: 0; }
@@ -25,13 +41,24 @@ const indexTmpl string = `
     {{- end}}
   </head>
   <body>
+    {{- if .BodyHTML}}
+    {{.BodyHTML}}
+    {{- end}}
     <div id="swagger-ui"></div>
-    <script src="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/4.1.3/swagger-ui-bundle.js"></script>
-    <script src="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/4.1.3/swagger-ui-standalone-preset.js"></script>
+    <script src="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/{{.SwaggerUIVersion}}/swagger-ui-bundle.js"></script>
+    <script src="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/{{.SwaggerUIVersion}}/swagger-ui-standalone-preset.js"></script>
     <script>
     window.onload = function() {
-      const config = {{.}};
+      const config = {{if .ConfigURL}}{ configUrl: {{.ConfigURL}} }{{else}}{{.}}{{end}};
       config.dom_id = '#swagger-ui';
+      const specURL = config.url;
+      {{- if .OAuth2RedirectPath}}
+      config.oauth2RedirectUrl = new URL({{.OAuth2RedirectPath}}, window.location.href).href;
+      {{- end}}
+      {{- if .Spec}}
+      config.spec = {{.Spec}};
+      delete config.url;
+      {{- end}}
       config.plugins = [
         {{- range $plugin := .Plugins }}
           {{$plugin}},
@@ -48,9 +75,28 @@ const indexTmpl string = `
       {{if .OnComplete}} config.onComplete = {{.OnComplete}}; {{end}}
       {{if .RequestInterceptor}} config.requestInterceptor = {{.RequestInterceptor}}; {{end}}
       {{if .ResponseInterceptor}} config.responseInterceptor = {{.ResponseInterceptor}}; {{end}}
+      {{- if .FriendlyTryItErrors}}
+      const responseInterceptor = config.responseInterceptor;
+      const describeError = function(response) {
+        if (!response || response.ok) {
+          return response;
+        }
+        const body = typeof response.text === 'string' ? response.text.trim() : '';
+        const snippet = body.length > 200 ? body.slice(0, 200) + '...' : body;
+        response.statusText = 'HTTP ' + response.status + (response.statusText ? ' ' + response.statusText : '') + (snippet ? ': ' + snippet : '');
+        return response;
+      };
+      config.responseInterceptor = function(response) {
+        const intercepted = responseInterceptor ? responseInterceptor(response) : response;
+        return intercepted && typeof intercepted.then === 'function' ? intercepted.then(describeError) : describeError(intercepted);
+      };
+      {{- end}}
       {{if .ModelPropertyMacro}} config.modelPropertyMacro = {{.ModelPropertyMacro}}; {{end}}
       {{if .ParameterMacro}} config.parameterMacro = {{.ParameterMacro}}; {{end}}
 
+      {{- if .PreInitScript}}
+      {{.PreInitScript}}
+      {{- end}}
       const ui = SwaggerUIBundle(config);
 
       {{if .OAuth}} ui.initOAuth({{.OAuth}}); {{end}}
@@ -58,8 +104,236 @@ const indexTmpl string = `
       {{if .PreauthorizeApiKey}} ui.preauthorizeApiKey({{.PreauthorizeApiKey}}); {{end}}
 
       window.ui = ui;
+      {{- if .AutoRefreshInterval}}
+
+      let specETag = null;
+      let specText = null;
+      setInterval(function() {
+        const url = ui.specSelectors.url() || specURL;
+        const headers = specETag ? { 'If-None-Match': specETag } : {};
+        fetch(url, { cache: 'no-cache', headers: headers })
+          .then(function(response) {
+            if (response.status !== 200) {
+              return;
+            }
+            const etag = response.headers.get('ETag');
+            if (!etag) {
+              // Without an ETag, e.g. with DisableBuiltinETag, the documents are compared.
+              return response.text().then(function(spec) {
+                if (specText !== null && spec !== specText) {
+                  ui.specActions.updateSpec(spec);
+                }
+                specText = spec;
+              });
+            }
+            if (specETag === null || etag === specETag) {
+              specETag = etag;
+              return;
+            }
+            specETag = etag;
+            return response.text().then(function(spec) {
+              ui.specActions.updateSpec(spec);
+            });
+          })
+          .catch(function() {});
+      }, {{.AutoRefreshInterval.Milliseconds}});
+      {{- end}}
     }
     </script>
   </body>
 </html>
 `
+
+// redocTmpl is the HTML template for the ReDoc page, served from the same spec document.
+const redocTmpl string = `
+<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.Title}}</title>
+    {{- if .HeadHTML}}
+    {{.HeadHTML}}
+    {{- end}}
+    <link href="https://fonts.googleapis.com/css?family=Montserrat:300,400,700|Roboto:300,400,700" rel="stylesheet">
+    <style>
+      body { margin: 0; padding: 0; }
+      {{- if .CustomStyle}}
+      {{.CustomStyle}}
+      {{- end}}
+    </style>
+    {{- if .CustomScript}}
+      <script>
+        {{.CustomScript}}
+      </script>
+    {{- end}}
+  </head>
+  <body>
+    {{- if .BodyHTML}}
+    {{.BodyHTML}}
+    {{- end}}
+    <redoc spec-url="{{.URL}}"></redoc>
+    <script src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"></script>
+  </body>
+</html>
+`
+
+// rapidocTmpl is the HTML template for the RapiDoc page, served from the same spec document.
+const rapidocTmpl string = `
+<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.Title}}</title>
+    {{- if .HeadHTML}}
+    {{.HeadHTML}}
+    {{- end}}
+    {{- if .CustomStyle}}
+      <style>
+        {{.CustomStyle}}
+      </style>
+    {{- end}}
+    {{- if .CustomScript}}
+      <script>
+        {{.CustomScript}}
+      </script>
+    {{- end}}
+    <script type="module" src="https://unpkg.com/rapidoc@9.3.4/dist/rapidoc-min.js"></script>
+  </head>
+  <body>
+    {{- if .BodyHTML}}
+    {{.BodyHTML}}
+    {{- end}}
+    <rapi-doc spec-url="{{.URL}}"></rapi-doc>
+  </body>
+</html>
+`
+
+// asyncAPITmpl is the HTML template for the AsyncAPI page, rendered with the AsyncAPI standalone component.
+const asyncAPITmpl string = `
+<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.Title}}</title>
+    {{- if .HeadHTML}}
+    {{.HeadHTML}}
+    {{- end}}
+    <link rel="stylesheet" href="https://unpkg.com/@asyncapi/react-component@1.4.10/styles/default.min.css">
+    {{- if .CustomStyle}}
+      <style>
+        {{.CustomStyle}}
+      </style>
+    {{- end}}
+    {{- if .CustomScript}}
+      <script>
+        {{.CustomScript}}
+      </script>
+    {{- end}}
+  </head>
+  <body>
+    {{- if .BodyHTML}}
+    {{.BodyHTML}}
+    {{- end}}
+    <div id="asyncapi"></div>
+    <script src="https://unpkg.com/@asyncapi/react-component@1.4.10/browser/standalone/index.js"></script>
+    <script>
+      AsyncApiStandalone.render({
+        schema: {
+          url: {{.URL}},
+          options: { method: 'GET', mode: 'cors' },
+        },
+        config: {
+          show: { sidebar: true },
+        },
+      }, document.getElementById('asyncapi'));
+    </script>
+  </body>
+</html>
+`
+
+// oauth2RedirectTmpl is the page OAuth2 authorization servers redirect back to, handing the
+// result to the Swagger UI window that opened it. It is taken from the Swagger UI distribution.
+const oauth2RedirectTmpl string = `
+<!doctype html>
+<html lang="en-US">
+<head>
+    <title>Swagger UI: OAuth2 Redirect</title>
+</head>
+<body>
+<script>
+    'use strict';
+    function run () {
+        var oauth2 = window.opener.swaggerUIRedirectOauth2;
+        var sentState = oauth2.state;
+        var redirectUrl = oauth2.redirectUrl;
+        var isValid, qp, arr;
+
+        if (/code|token|error/.test(window.location.hash)) {
+            qp = window.location.hash.substring(1).replace('?', '&');
+        } else {
+            qp = location.search.substring(1);
+        }
+
+        arr = qp.split("&");
+        arr.forEach(function (v,i,_arr) { _arr[i] = '"' + v.replace('=', '":"') + '"';});
+        qp = qp ? JSON.parse('{' + arr.join() + '}',
+                function (key, value) {
+                    return key === "" ? value : decodeURIComponent(value);
+                }
+        ) : {};
+
+        isValid = qp.state === sentState;
+
+        if ((
+          oauth2.auth.schema.get("flow") === "accessCode" ||
+          oauth2.auth.schema.get("flow") === "authorizationCode" ||
+          oauth2.auth.schema.get("flow") === "authorization_code"
+        ) && !oauth2.auth.code) {
+            if (!isValid) {
+                oauth2.errCb({
+                    authId: oauth2.auth.name,
+                    source: "auth",
+                    level: "warning",
+                    message: "Authorization may be unsafe, passed state was changed in server. The passed state wasn't returned from auth server."
+                });
+            }
+
+            if (qp.code) {
+                delete oauth2.state;
+                oauth2.auth.code = qp.code;
+                oauth2.callback({auth: oauth2.auth, redirectUrl: redirectUrl});
+            } else {
+                let oauthErrorMsg;
+                if (qp.error) {
+                    oauthErrorMsg = "["+qp.error+"]: " +
+                        (qp.error_description ? qp.error_description+ ". " : "no accessCode received from the server. ") +
+                        (qp.error_uri ? "More info: "+qp.error_uri : "");
+                }
+
+                oauth2.errCb({
+                    authId: oauth2.auth.name,
+                    source: "auth",
+                    level: "error",
+                    message: oauthErrorMsg || "[Authorization failed]: no accessCode received from the server."
+                });
+            }
+        } else {
+            oauth2.callback({auth: oauth2.auth, token: qp, isValid: isValid, redirectUrl: redirectUrl});
+        }
+        window.close();
+    }
+
+    if (document.readyState !== 'loading') {
+        run();
+    } else {
+        document.addEventListener('DOMContentLoaded', function () {
+            run();
+        });
+    }
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,89 @@
+package swagger
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// localize returns cfg with the title and, when Config.LocalizeSpecInfo is set,
+// the spec info of the Localized entry best matching the request's Accept-Language.
+// cfg is returned unchanged when no entry applies.
+func localize(c fiber.Ctx, cfg Config) Config {
+	if len(cfg.Localized) == 0 {
+		return cfg
+	}
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	info, ok := cfg.Localized[matchLanguage(c.Get(fiber.HeaderAcceptLanguage), cfg.Localized)]
+	if !ok {
+		if info, ok = cfg.Localized[cfg.DefaultLocale]; !ok {
+			return cfg
+		}
+	}
+
+	if info.Title != "" {
+		cfg.Title = info.Title
+	}
+	if cfg.LocalizeSpecInfo {
+		// Copy the override, the pointer is shared with other requests.
+		override := InfoOverride{}
+		if cfg.InfoOverride != nil {
+			override = *cfg.InfoOverride
+		}
+		if info.Title != "" {
+			override.Title = info.Title
+		}
+		if info.Description != "" {
+			override.Description = info.Description
+		}
+		cfg.InfoOverride = &override
+	}
+	return cfg
+}
+
+// matchLanguage returns the key of locales best matching an Accept-Language
+// header, or "" if none does. Tags are tried by descending quality; a tag
+// matches a key that is equal to it or to its primary language, so "de-AT"
+// falls back to "de".
+func matchLanguage(header string, locales map[string]LocalizedInfo) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if tag != "" && tag != "*" && q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		primary, _, _ := strings.Cut(t.tag, "-")
+		var fallback string
+		for key := range locales {
+			if strings.EqualFold(key, t.tag) {
+				return key
+			}
+			if strings.EqualFold(key, primary) {
+				fallback = key
+			}
+		}
+		if fallback != "" {
+			return fallback
+		}
+	}
+	return ""
+}
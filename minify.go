@@ -0,0 +1,147 @@
+package swagger
+
+import (
+	"bytes"
+)
+
+// rawTextElements lists the elements whose content is copied verbatim by minifyHTML,
+// since collapsing whitespace inside them can change their meaning.
+var rawTextElements = []string{"script", "style", "pre", "textarea"}
+
+// minifyHTML strips comments and collapses whitespace from the rendered HTML.
+// Runs of whitespace, including those between tags, become a single space so
+// the rendered text is unchanged. Tags are copied verbatim, so attribute values
+// are never altered, and the content of script, style, pre and textarea
+// elements is left untouched so inline scripts keep working.
+func minifyHTML(src []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(src))
+
+	for i := 0; i < len(src); {
+		if hasPrefixFold(src[i:], "<!--") {
+			end := bytes.Index(src[i+4:], []byte("-->"))
+			if end < 0 {
+				// An unterminated comment is copied through rather than swallowing the document.
+				buf.Write(src[i:])
+				break
+			}
+			i += 4 + end + 3
+			continue
+		}
+
+		if tag := rawTextElement(src[i:]); tag != "" {
+			end := indexFold(src[i+len(tag)+1:], "</"+tag)
+			if end < 0 {
+				buf.Write(src[i:])
+				break
+			}
+			end += i + len(tag) + 1
+			buf.Write(src[i:end])
+			i = end
+			continue
+		}
+
+		if end := tagEnd(src[i:]); end > 0 {
+			buf.Write(src[i : i+end])
+			i += end
+			continue
+		}
+
+		if !isSpace(src[i]) {
+			buf.WriteByte(src[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(src) && isSpace(src[j]) {
+			j++
+		}
+		// Whitespace at the edges of the document is dropped, any other run of
+		// whitespace is collapsed into a single space.
+		if buf.Len() > 0 && j < len(src) && buf.Bytes()[buf.Len()-1] != ' ' {
+			buf.WriteByte(' ')
+		}
+		i = j
+	}
+
+	return buf.Bytes()
+}
+
+// tagEnd returns the length of the tag at the start of b, or 0 if b does not
+// start with a tag. Quoted attribute values may contain '>' and are skipped.
+// An unterminated tag extends to the end of b.
+func tagEnd(b []byte) int {
+	if len(b) < 2 || b[0] != '<' {
+		return 0
+	}
+	if c := lower(b[1]); !('a' <= c && c <= 'z') && c != '/' && c != '!' && c != '?' {
+		return 0
+	}
+
+	var quote byte
+	for i := 1; i < len(b); i++ {
+		switch c := b[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(b)
+}
+
+// rawTextElement returns the name of the raw text element opened at the start of b, if any.
+func rawTextElement(b []byte) string {
+	if len(b) == 0 || b[0] != '<' {
+		return ""
+	}
+	for _, tag := range rawTextElements {
+		if !hasPrefixFold(b[1:], tag) || len(b) <= len(tag)+1 {
+			continue
+		}
+		switch b[len(tag)+1] {
+		case '>', '/', ' ', '\t', '\n', '\r':
+			return tag
+		}
+	}
+	return ""
+}
+
+// hasPrefixFold reports whether b begins with the ASCII prefix, ignoring case.
+func hasPrefixFold(b []byte, prefix string) bool {
+	if len(b) < len(prefix) {
+		return false
+	}
+	for i := 0; i < len(prefix); i++ {
+		if lower(b[i]) != lower(prefix[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// indexFold returns the index of the first ASCII case-insensitive occurrence of s in b, or -1.
+func indexFold(b []byte, s string) int {
+	for i := 0; i+len(s) <= len(b); i++ {
+		if hasPrefixFold(b[i:], s) {
+			return i
+		}
+	}
+	return -1
+}
+
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,1028 @@
+package swagger
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/swaggo/swag"
+)
+
+const (
+	// defaultSpecSizeLimit is the default maximum size of a spec document read from a file or remote URL.
+	defaultSpecSizeLimit = 32 << 20
+
+	// defaultSpecCacheSize is the default number of spec document variants cached per handler.
+	defaultSpecCacheSize = 64
+
+	// defaultRemoteSpecTimeout bounds remote fetches when Config.SpecReadTimeout is not set,
+	// so a hung upstream cannot stall the refresh forever.
+	defaultRemoteSpecTimeout = 30 * time.Second
+
+	// defaultRemoteSpecTTL is the default duration a spec document fetched from a remote URL is cached.
+	defaultRemoteSpecTTL = 5 * time.Minute
+)
+
+var (
+	// ErrSpecTooLarge is returned when a spec document exceeds Config.SpecSizeLimit.
+	ErrSpecTooLarge = fiber.NewError(fiber.StatusRequestEntityTooLarge, "spec document exceeds the configured size limit")
+
+	// ErrSpecFile is returned when the spec document cannot be read from Config.FilePath. It wraps
+	// the cause, e.g. fs.ErrNotExist, but does not name the path, as it may reach the client.
+	ErrSpecFile = fiber.NewError(fiber.StatusInternalServerError, "spec file cannot be read")
+
+	// ErrSpecUpstream is returned when the spec document cannot be fetched from Config.RemoteSpecURL.
+	// It does not name the upstream, as it may reach the client.
+	ErrSpecUpstream = fiber.NewError(fiber.StatusBadGateway, "spec upstream unavailable")
+
+	// ErrSpecReadTimeout is returned when reading a spec document takes longer than Config.SpecReadTimeout.
+	ErrSpecReadTimeout = fiber.NewError(fiber.StatusGatewayTimeout, "spec document read timed out")
+)
+
+// specDoc is a loaded spec document together with its cache validators.
+type specDoc struct {
+	body        []byte
+	contentType string
+	etag        string
+	modTime     time.Time
+
+	// source is the checksum of the raw document body was prepared from, which
+	// tells whether a swag document without modification time changed.
+	source string
+
+	// gzipped is the gzip encoding of body, compressed on first use.
+	gzipOnce sync.Once
+	gzipped  []byte
+
+	// version is the info.version of body, parsed on first use.
+	versionOnce sync.Once
+	version     string
+
+	// ndjsonPaths is the paths object of body as NDJSON, encoded on first use.
+	ndjsonOnce  sync.Once
+	ndjsonPaths []byte
+	ndjsonErr   error
+
+	// schemas are the component schemas of body, parsed on first use.
+	schemasOnce sync.Once
+	schemas     map[string]json.RawMessage
+}
+
+func newSpecDoc(body []byte, modTime time.Time) *specDoc {
+	return &specDoc{
+		body:        body,
+		contentType: fiber.MIMEApplicationJSON,
+		etag:        fmt.Sprintf(`"%s"`, checksum(body)),
+		modTime:     modTime,
+	}
+}
+
+// checksum returns a short content hash of body.
+func checksum(body []byte) string {
+	return fmt.Sprintf("%d-%08x", len(body), crc32.ChecksumIEEE(body))
+}
+
+// gzip returns the gzip encoding of the document, compressing it only once.
+func (d *specDoc) gzip() []byte {
+	d.gzipOnce.Do(func() {
+		d.gzipped = gzipBytes(d.body)
+	})
+	return d.gzipped
+}
+
+// infoVersion returns the info.version of the document, or "" if it has none.
+// The document is parsed only once.
+func (d *specDoc) infoVersion() string {
+	d.versionOnce.Do(func() {
+		var doc struct {
+			Info struct {
+				Version string `json:"version"`
+			} `json:"info"`
+		}
+		// Documents that are not JSON simply have no version to advertise.
+		_ = json.Unmarshal(d.body, &doc)
+		d.version = doc.Info.Version
+	})
+	return d.version
+}
+
+// pathsNDJSON returns the entries of the paths object of the document in
+// document order, one {"path":...,"item":...} object per line. The document is
+// encoded only once.
+func (d *specDoc) pathsNDJSON() ([]byte, error) {
+	d.ndjsonOnce.Do(func() {
+		d.ndjsonPaths, d.ndjsonErr = encodePathsNDJSON(d.body)
+	})
+	return d.ndjsonPaths, d.ndjsonErr
+}
+
+// schema returns the named component schema of the document, read from
+// components.schemas or, for swagger 2.0 documents, from definitions.
+// The schemas are parsed only once.
+func (d *specDoc) schema(name string) (json.RawMessage, bool) {
+	d.schemasOnce.Do(func() {
+		var doc struct {
+			Components struct {
+				Schemas map[string]json.RawMessage `json:"schemas"`
+			} `json:"components"`
+			Definitions map[string]json.RawMessage `json:"definitions"`
+		}
+		// Documents that are not JSON simply have no schemas to serve.
+		_ = json.Unmarshal(d.body, &doc)
+
+		d.schemas = doc.Components.Schemas
+		if d.schemas == nil {
+			d.schemas = doc.Definitions
+		}
+	})
+
+	schema, ok := d.schemas[name]
+	return schema, ok
+}
+
+// specStore caches the spec documents served by a handler, keyed by swag instance
+// name and the parameters of the one-time transformations (see specKey). Once it
+// holds more than size documents, the least recently used one is evicted.
+type specStore struct {
+	mu    sync.Mutex
+	size  int
+	docs  map[string]*list.Element
+	order *list.List
+
+	// refreshes are the reads and remote fetches in flight, keyed like docs.
+	refreshes map[string]*specRefresh
+
+	// statMu guards stats, the file stats in flight keyed by path. It is separate
+	// from mu so waiting for a stat never blocks on a document being read.
+	statMu sync.Mutex
+	stats  map[string]*specStat
+
+	// generation is the cacheGeneration the cached documents were loaded in.
+	generation uint64
+
+	// ready guards the single invocation of Config.OnReady.
+	ready sync.Once
+}
+
+// specRefresh is a read or remote fetch in flight. doc and err are set before done is closed.
+type specRefresh struct {
+	done chan struct{}
+	doc  *specDoc
+	err  error
+}
+
+// specStat is a file stat in flight. modTime and err are set before done is closed.
+type specStat struct {
+	done    chan struct{}
+	modTime time.Time
+	err     error
+}
+
+// specEntry is a cached document together with its key, kept in specStore.order.
+// Cached documents are shared by concurrent requests and never modified, so the
+// per-entry state lives here, guarded by specStore.mu.
+type specEntry struct {
+	key string
+	doc *specDoc
+
+	// expires is the time a document fetched from a remote URL is refreshed at.
+	expires time.Time
+}
+
+func newSpecStore(size int) *specStore {
+	return &specStore{
+		size:       size,
+		docs:       make(map[string]*list.Element),
+		order:      list.New(),
+		refreshes:  make(map[string]*specRefresh),
+		stats:      make(map[string]*specStat),
+		generation: cacheGeneration.Load(),
+	}
+}
+
+// cacheGeneration is advanced by ClearCache, which makes every specStore drop
+// its documents on next use.
+var cacheGeneration atomic.Uint64
+
+// ClearCache flushes the cached spec documents of all handlers, so they are
+// read, fetched and transformed again on the next request.
+func ClearCache() {
+	cacheGeneration.Add(1)
+}
+
+// get returns the cache entry for key and marks it as recently used.
+// The caller must hold s.mu.
+func (s *specStore) get(key string) (*specEntry, bool) {
+	if generation := cacheGeneration.Load(); generation != s.generation {
+		s.generation = generation
+		s.docs = make(map[string]*list.Element)
+		s.order.Init()
+	}
+
+	elem, ok := s.docs[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*specEntry), true
+}
+
+// put caches doc under key until expires, evicting the least recently used
+// documents beyond the size limit. The caller must hold s.mu and have called get for key.
+func (s *specStore) put(key string, doc *specDoc, expires time.Time) {
+	if elem, ok := s.docs[key]; ok {
+		entry := elem.Value.(*specEntry)
+		entry.doc, entry.expires = doc, expires
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	s.docs[key] = s.order.PushFront(&specEntry{key: key, doc: doc, expires: expires})
+	for s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.docs, oldest.Value.(*specEntry).key)
+	}
+}
+
+// load returns the spec document of the named instance. The cached copy is
+// reused until its modification time advances. Documents read from cfg.FilePath
+// default to the modification time of the file, while swag documents have none
+// without cfg.SpecModTimeFunc (see loadSwag).
+// Only one read per key runs at a time and s.mu is not held during it, so a hung
+// file delays only the requests for that document, each by at most cfg.SpecReadTimeout.
+func (s *specStore) load(cfg Config, name string) (*specDoc, error) {
+	key := specKey(cfg, name)
+	if cfg.RemoteSpecURL != "" {
+		return s.loadRemote(cfg, key)
+	}
+	if cfg.FilePath == "" && cfg.SpecModTimeFunc == nil {
+		return s.loadSwag(cfg, name, key)
+	}
+
+	modTime, err := s.modTime(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if entry, ok := s.get(key); ok && !modTime.After(entry.doc.modTime) {
+		s.mu.Unlock()
+		return entry.doc, nil
+	}
+	r, running := s.refreshes[key]
+	if !running {
+		r = &specRefresh{done: make(chan struct{})}
+		s.refreshes[key] = r
+	}
+	s.mu.Unlock()
+
+	// swag documents are read from memory, so only file reads are bounded.
+	timeout := time.Duration(0)
+	if cfg.FilePath != "" {
+		timeout = cfg.SpecReadTimeout
+	}
+
+	if !running {
+		read := func() {
+			doc, err := readSpecDoc(cfg, name, modTime)
+
+			s.mu.Lock()
+			if err == nil {
+				s.put(key, doc, time.Time{})
+			}
+			delete(s.refreshes, key)
+			s.mu.Unlock()
+
+			r.doc, r.err = doc, err
+			close(r.done)
+		}
+		// A file read cannot be interrupted, so with a timeout it outlives the
+		// requests waiting for it and runs on its own goroutine.
+		if timeout > 0 {
+			go read()
+		} else {
+			read()
+		}
+	}
+
+	if err := waitTimeout(r.done, timeout); err != nil {
+		return nil, err
+	}
+	return r.doc, r.err
+}
+
+// loadSwag returns the swag document of the named instance. It is read on every
+// request, since swag.SwaggerInfo may be changed at any time, and the transformed
+// copy is reused while the raw document is unchanged.
+func (s *specStore) loadSwag(cfg Config, name, key string) (*specDoc, error) {
+	raw, err := readSpec(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	source := checksum(raw)
+
+	s.mu.Lock()
+	entry, ok := s.get(key)
+	s.mu.Unlock()
+	if ok && entry.doc.source == source {
+		return entry.doc, nil
+	}
+
+	doc, err := prepareSpecDoc(cfg, raw, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	doc.source = source
+
+	s.mu.Lock()
+	s.put(key, doc, time.Time{})
+	s.mu.Unlock()
+	return doc, nil
+}
+
+// readSpecDoc reads the spec document from the configured source and applies
+// the one-time transformations.
+func readSpecDoc(cfg Config, name string, modTime time.Time) (*specDoc, error) {
+	raw, err := readSpec(cfg, name)
+	if err != nil {
+		return nil, err
+	}
+	return prepareSpecDoc(cfg, raw, modTime)
+}
+
+// prepareSpecDoc applies the one-time transformations to the raw document.
+func prepareSpecDoc(cfg Config, raw []byte, modTime time.Time) (*specDoc, error) {
+	body, err := prepareSpec(cfg, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := newSpecDoc(body, modTime)
+	doc.contentType = specContentType(cfg)
+	return doc, nil
+}
+
+// loadRemote returns the spec document fetched from cfg.RemoteSpecURL, fetching
+// it again once cfg.RemoteSpecTTL elapsed. When the upstream fails, the last good
+// copy or else cfg.RemoteSpecFallback is served until the next refresh.
+// Only one fetch per key runs at a time and s.mu is not held during it, so while
+// an expired copy is refreshed, other requests are served that copy right away.
+func (s *specStore) loadRemote(cfg Config, key string) (*specDoc, error) {
+	s.mu.Lock()
+	var cached *specDoc
+	entry, ok := s.get(key)
+	if ok {
+		cached = entry.doc
+		if cfg.now().Before(entry.expires) {
+			s.mu.Unlock()
+			return cached, nil
+		}
+	}
+	if r, running := s.refreshes[key]; running {
+		s.mu.Unlock()
+		if ok {
+			return cached, nil
+		}
+		<-r.done
+		return r.doc, r.err
+	}
+	r := &specRefresh{done: make(chan struct{})}
+	s.refreshes[key] = r
+	s.mu.Unlock()
+
+	body, err := fetchSpec(cfg)
+	if err == nil {
+		body, err = prepareSpec(cfg, body)
+	}
+
+	// New documents get their content type before they are shared; the cached
+	// copy is already being served and is reused as is.
+	var doc *specDoc
+	switch {
+	case err == nil:
+		doc = newSpecDoc(body, time.Time{})
+		doc.contentType = specContentType(cfg)
+	case ok:
+		doc, err = cached, nil
+	case cfg.RemoteSpecFallback != "":
+		var fallback []byte
+		if fallback, err = prepareSpec(cfg, []byte(cfg.RemoteSpecFallback)); err == nil {
+			doc = newSpecDoc(fallback, time.Time{})
+			doc.contentType = specContentType(cfg)
+		}
+	}
+
+	s.mu.Lock()
+	if doc != nil {
+		s.put(key, doc, cfg.now().Add(cfg.RemoteSpecTTL))
+	}
+	delete(s.refreshes, key)
+	s.mu.Unlock()
+
+	r.doc, r.err = doc, err
+	close(r.done)
+	return doc, err
+}
+
+// specKey returns the cache key of the document served for the named instance,
+// so documents derived with different per-request parameters are cached separately.
+func specKey(cfg Config, name string) string {
+	key := name
+	if cfg.InfoOverride != nil {
+		override, _ := json.Marshal(cfg.InfoOverride)
+		key += "\x00info=" + string(override)
+	}
+	if len(cfg.RedactPaths) > 0 {
+		key += "\x00redact=" + strings.Join(cfg.RedactPaths, "\x00")
+	}
+	return key
+}
+
+// specContentType returns the content type of the configured spec source,
+// derived from the extension of the file or remote URL.
+func specContentType(cfg Config) string {
+	source := cfg.FilePath
+	if cfg.RemoteSpecURL != "" {
+		source = cfg.RemoteSpecURL
+		if u, err := url.Parse(source); err == nil {
+			source = u.Path
+		}
+	}
+
+	switch strings.ToLower(path.Ext(source)) {
+	case ".yaml", ".yml":
+		return "application/yaml"
+	default:
+		return fiber.MIMEApplicationJSON
+	}
+}
+
+// modTime returns the modification time of the configured spec source.
+func (s *specStore) modTime(cfg Config) (time.Time, error) {
+	if cfg.SpecModTimeFunc != nil {
+		return cfg.SpecModTimeFunc(), nil
+	}
+	if cfg.FilePath == "" {
+		return time.Time{}, nil
+	}
+	if cfg.SpecReadTimeout <= 0 {
+		return fileModTime(cfg.FilePath)
+	}
+
+	// A stat of a hung file outlives the requests waiting for it, so concurrent
+	// requests share the one in flight instead of each leaving a goroutine behind.
+	s.statMu.Lock()
+	st, ok := s.stats[cfg.FilePath]
+	if !ok {
+		st = &specStat{done: make(chan struct{})}
+		s.stats[cfg.FilePath] = st
+		go func() {
+			st.modTime, st.err = fileModTime(cfg.FilePath)
+			s.statMu.Lock()
+			delete(s.stats, cfg.FilePath)
+			s.statMu.Unlock()
+			close(st.done)
+		}()
+	}
+	s.statMu.Unlock()
+
+	if err := waitTimeout(st.done, cfg.SpecReadTimeout); err != nil {
+		return time.Time{}, err
+	}
+	return st.modTime, st.err
+}
+
+// fileModTime returns the modification time of the file at path.
+func fileModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fileError(err)
+	}
+	return info.ModTime(), nil
+}
+
+// readSpec reads the raw spec document from the configured source.
+func readSpec(cfg Config, name string) ([]byte, error) {
+	if cfg.FilePath != "" {
+		return readSpecFile(cfg.FilePath, cfg.SpecSizeLimit)
+	}
+
+	doc, err := swag.ReadDoc(name)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(doc), nil
+}
+
+// readSpecFile reads the spec document at path.
+func readSpecFile(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fileError(err)
+	}
+	defer f.Close()
+
+	body, err := readLimited(f, limit)
+	if err != nil {
+		return nil, fileError(err)
+	}
+	return body, nil
+}
+
+// fileError replaces the path error of a spec file operation with ErrSpecFile,
+// keeping the underlying cause.
+func fileError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return fmt.Errorf("%w: %w", ErrSpecFile, pathErr.Err)
+	}
+	return err
+}
+
+// fetchSpec fetches the spec document from cfg.RemoteSpecURL, bounded by
+// cfg.SpecReadTimeout or else defaultRemoteSpecTimeout. Failures other than the
+// timeout and the size limit are reported as ErrSpecUpstream.
+func fetchSpec(cfg Config) ([]byte, error) {
+	timeout := cfg.SpecReadTimeout
+	if timeout <= 0 {
+		timeout = defaultRemoteSpecTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	body, err := func() ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.RemoteSpecURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, ErrSpecUpstream
+		}
+		return readLimited(resp.Body, cfg.SpecSizeLimit)
+	}()
+	switch {
+	case err == nil, errors.Is(err, ErrSpecTooLarge):
+		return body, err
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, ErrSpecReadTimeout
+	default:
+		// Transport errors name the URL, so they are not passed on.
+		return nil, ErrSpecUpstream
+	}
+}
+
+// prepareSpec applies the configured one-time transformations to a freshly read
+// document, before it is cached and its ETag is computed. They run in the order
+// RedactPaths, Sanitizer, InfoOverride, NormalizeSpec, DocJSONIndent.
+func prepareSpec(cfg Config, body []byte) ([]byte, error) {
+	if len(cfg.RedactPaths) > 0 {
+		var err error
+		if body, err = redactJSON(body, cfg.RedactPaths); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Sanitizer != nil {
+		var err error
+		if body, err = cfg.Sanitizer(body); err != nil {
+			return nil, fmt.Errorf("sanitize spec: %w", err)
+		}
+	}
+	if cfg.InfoOverride != nil {
+		var err error
+		if body, err = overrideInfo(body, cfg.InfoOverride); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.NormalizeSpec {
+		var err error
+		if body, err = normalizeJSON(body); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.DocJSONIndent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, body, "", cfg.DocJSONIndent); err != nil {
+			return nil, fmt.Errorf("indent spec: %w", err)
+		}
+		body = buf.Bytes()
+	}
+	return body, nil
+}
+
+// encodePathsNDJSON encodes the entries of the paths object of a JSON document
+// as NDJSON. A json.Decoder walks the document, since decoding into a map would
+// lose the order of the paths.
+func encodePathsNDJSON(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, fmt.Errorf("paths ndjson: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("paths ndjson: %w", err)
+		}
+		if key != "paths" {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return nil, fmt.Errorf("paths ndjson: %w", err)
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, fmt.Errorf("paths ndjson: %w", err)
+		}
+		for dec.More() {
+			path, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("paths ndjson: %w", err)
+			}
+			var item json.RawMessage
+			if err := dec.Decode(&item); err != nil {
+				return nil, fmt.Errorf("paths ndjson: %w", err)
+			}
+
+			encodedPath, err := marshalJSON(path)
+			if err != nil {
+				return nil, fmt.Errorf("paths ndjson: %w", err)
+			}
+			buf.WriteString(`{"path":`)
+			buf.Write(encodedPath)
+			buf.WriteString(`,"item":`)
+			if err := json.Compact(&buf, item); err != nil {
+				return nil, fmt.Errorf("paths ndjson: %w", err)
+			}
+			buf.WriteString("}\n")
+		}
+		break
+	}
+	return buf.Bytes(), nil
+}
+
+// expectDelim reads the next token of dec and fails unless it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// redactJSON removes the fields at the given dotted paths from a JSON document.
+// Like encodePathsNDJSON it walks the document with a json.Decoder, so the order
+// of the remaining object keys is preserved.
+func redactJSON(body []byte, paths []string) ([]byte, error) {
+	patterns := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		patterns = append(patterns, strings.Split(p, "."))
+	}
+
+	var buf bytes.Buffer
+	if err := redact(&buf, body, patterns); err != nil {
+		return nil, fmt.Errorf("redact spec: %w", err)
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("redact spec: %w", err)
+	}
+	return compacted.Bytes(), nil
+}
+
+// redact writes the JSON value raw to buf without the fields matching the path
+// patterns. The last segment of a pattern names the object fields to remove;
+// array elements are never removed.
+func redact(buf *bytes.Buffer, raw json.RawMessage, patterns [][]string) error {
+	patterns = expandRecursive(patterns)
+	if len(patterns) == 0 {
+		buf.Write(raw)
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		buf.WriteByte('{')
+		first := true
+		for dec.More() {
+			key, value, err := nextMember(dec)
+			if err != nil {
+				return err
+			}
+
+			var children [][]string
+			removed := false
+			for _, p := range patterns {
+				switch {
+				case p[0] == "**":
+					children = append(children, p)
+				case p[0] != "*" && p[0] != key:
+				case len(p) == 1:
+					removed = true
+				default:
+					children = append(children, p[1:])
+				}
+			}
+			if removed {
+				continue
+			}
+
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			if err := writeKey(buf, key); err != nil {
+				return err
+			}
+			if err := redact(buf, value, children); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case json.Delim('['):
+		buf.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+
+			var children [][]string
+			for _, p := range patterns {
+				switch {
+				case p[0] == "**":
+					children = append(children, p)
+				case len(p) > 1 && (p[0] == "*" || p[0] == strconv.Itoa(i)):
+					children = append(children, p[1:])
+				}
+			}
+
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := redact(buf, value, children); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		buf.Write(raw)
+	}
+	return nil
+}
+
+// expandRecursive returns the patterns applying at the current level. A leading
+// "**" also matches zero levels, so the rest of such a pattern applies as well.
+func expandRecursive(patterns [][]string) [][]string {
+	var expanded [][]string
+	for _, p := range patterns {
+		for len(p) > 1 && p[0] == "**" {
+			expanded = append(expanded, p)
+			p = p[1:]
+		}
+		if len(p) > 0 && p[0] != "**" {
+			expanded = append(expanded, p)
+		}
+	}
+	return expanded
+}
+
+// overrideInfo replaces the non-empty fields of override in the info object of
+// the document. The order of the existing keys is preserved; missing fields, or
+// a missing info object, are appended.
+func overrideInfo(body []byte, override *InfoOverride) ([]byte, error) {
+	members, err := objectMembers(body)
+	if err != nil {
+		return nil, fmt.Errorf("override info: %w", err)
+	}
+
+	infoIndex := -1
+	var info []objectMember
+	for i, member := range members {
+		if member.key == "info" {
+			if info, err = objectMembers(member.value); err != nil {
+				return nil, fmt.Errorf("override info: %w", err)
+			}
+			infoIndex = i
+		}
+	}
+
+	for _, field := range []struct{ key, value string }{
+		{"title", override.Title},
+		{"version", override.Version},
+		{"description", override.Description},
+		{"termsOfService", override.TermsOfService},
+	} {
+		if field.value == "" {
+			continue
+		}
+		encoded, err := marshalJSON(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("override info: %w", err)
+		}
+		info = setMember(info, field.key, encoded)
+	}
+
+	encoded, err := encodeObject(info)
+	if err != nil {
+		return nil, fmt.Errorf("override info: %w", err)
+	}
+	if infoIndex < 0 {
+		members = append(members, objectMember{key: "info", value: encoded})
+	} else {
+		members[infoIndex].value = encoded
+	}
+
+	if body, err = encodeObject(members); err != nil {
+		return nil, fmt.Errorf("override info: %w", err)
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, body); err != nil {
+		return nil, fmt.Errorf("override info: %w", err)
+	}
+	return compacted.Bytes(), nil
+}
+
+// objectMember is a key and raw value of a JSON object.
+type objectMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// objectMembers returns the members of a JSON object in document order.
+func objectMembers(raw []byte) ([]objectMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var members []objectMember
+	for dec.More() {
+		key, value, err := nextMember(dec)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, objectMember{key: key, value: value})
+	}
+	return members, nil
+}
+
+// nextMember reads the next key and raw value of the object dec is in.
+func nextMember(dec *json.Decoder) (string, json.RawMessage, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return "", nil, err
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("expected an object key, got %v", token)
+	}
+
+	var value json.RawMessage
+	if err := dec.Decode(&value); err != nil {
+		return "", nil, err
+	}
+	return key, value, nil
+}
+
+// setMember replaces the value of key in members, or appends it.
+func setMember(members []objectMember, key string, value json.RawMessage) []objectMember {
+	for i := range members {
+		if members[i].key == key {
+			members[i].value = value
+			return members
+		}
+	}
+	return append(members, objectMember{key: key, value: value})
+}
+
+// encodeObject encodes members as a JSON object, in order.
+func encodeObject(members []objectMember) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeKey(&buf, member.key); err != nil {
+			return nil, err
+		}
+		buf.Write(member.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// writeKey writes key as a JSON object key followed by a colon.
+func writeKey(buf *bytes.Buffer, key string) error {
+	encoded, err := marshalJSON(key)
+	if err != nil {
+		return err
+	}
+	buf.Write(encoded)
+	buf.WriteByte(':')
+	return nil
+}
+
+// marshalJSON encodes v like json.Marshal, without escaping HTML characters.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// normalizeJSON re-encodes a JSON document with sorted object keys, so documents
+// with the same content always produce the same bytes. Array order is preserved
+// and numbers are kept verbatim.
+func normalizeJSON(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("normalize spec: %w", err)
+	}
+
+	normalized, err := marshalJSON(v)
+	if err != nil {
+		return nil, fmt.Errorf("normalize spec: %w", err)
+	}
+	return normalized, nil
+}
+
+// readLimited reads the spec document from r, failing with ErrSpecTooLarge
+// when it is bigger than limit bytes. A negative limit disables the check.
+// The error does not name the source, as it may reach the client.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit < 0 {
+		return io.ReadAll(r)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, ErrSpecTooLarge
+	}
+	return body, nil
+}
+
+// waitTimeout waits until done is closed and fails with ErrSpecReadTimeout once
+// timeout elapses, which lets a request fail cleanly on a hung source while the
+// read keeps running in the background. A non-positive timeout waits indefinitely.
+func waitTimeout(done <-chan struct{}, timeout time.Duration) error {
+	if timeout <= 0 {
+		<-done
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		return ErrSpecReadTimeout
+	}
+}
@@ -3,19 +3,38 @@
 package swagger
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"net/http"
+	"net/url"
 	"path"
+	"regexp"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
 	"github.com/swaggo/swag"
 )
 
 const (
 	defaultDocURL = "doc.json"
 	defaultIndex  = "index.html"
+	redocIndex    = "redoc.html"
+	rapidocIndex  = "rapidoc.html"
+
+	// oauth2RedirectIndex is the OAuth2 redirect page of Swagger UI, the default Config.OAuth2RedirectUrl.
+	oauth2RedirectIndex = "oauth2-redirect.html"
+
+	// componentSchemasPath is the path prefix of the component schemas served when Config.ServeComponents is enabled.
+	componentSchemasPath = "components/schemas/"
+
+	// swaggerConfigURL serves the settings of Swagger UI when Config.ExternalizeConfig is enabled.
+	swaggerConfigURL = "swagger-config.json"
 )
 
 // HandlerDefault is the default Swagger handler generated by New().
@@ -26,8 +45,13 @@ var HandlerDefault = New()
 // custom plugins, and UI layout.
 //
 // The returned handler serves the Swagger documentation and the UI based on
-// the specified configuration. It initializes a template for the Swagger UI
-// index page and handles requests for the Swagger JSON documentation.
+// the specified configuration. It initializes the templates for the Swagger UI
+// index page, the ReDoc ("redoc.html") and RapiDoc ("rapidoc.html") pages and
+// handles requests for the Swagger JSON documentation shared by all of them.
+//
+// The prefix used for the links rendered in the UI is resolved on every request
+// from the matched route and the "X-Forwarded-Prefix" header, so the same
+// handler can safely be mounted under several paths.
 //
 // Usage:
 //
@@ -36,50 +60,257 @@ var HandlerDefault = New()
 func New(config ...Config) fiber.Handler {
 	cfg := configDefault(config...)
 
-	index, err := template.New("swagger_index.html").Parse(indexTmpl)
-	if err != nil {
-		panic(fmt.Errorf("fiber: swagger middleware error -> %w", err))
+	return newHandler(cfg, map[string]*template.Template{
+		defaultIndex:        mustParseTemplate("swagger_index.html", indexTmpl),
+		redocIndex:          mustParseTemplate("swagger_redoc.html", redocTmpl),
+		rapidocIndex:        mustParseTemplate("swagger_rapidoc.html", rapidocTmpl),
+		oauth2RedirectIndex: mustParseTemplate("swagger_oauth2_redirect.html", oauth2RedirectTmpl),
+	})
+}
+
+// NewAsyncAPI returns a Fiber handler serving an AsyncAPI document with the
+// AsyncAPI standalone renderer at "index.html". The document is read from
+// Config.FilePath or Config.RemoteSpecURL instead of a swag instance, and served
+// at "doc.json" with a YAML or JSON content type depending on its extension.
+// Prefix resolution and caching work the same as with New.
+//
+// Usage:
+//
+//	app := fiber.New()
+//	app.Get("/events/*", swagger.NewAsyncAPI(swagger.Config{FilePath: "./asyncapi.yaml"}))
+func NewAsyncAPI(config ...Config) fiber.Handler {
+	if len(config) > 0 && config[0].Title == "" {
+		// Default a copy, the caller's slice may be reused for other handlers.
+		local := config[0]
+		local.Title = "AsyncAPI"
+		config = []Config{local}
 	}
+	cfg := configDefault(config...)
+	if cfg.FilePath == "" && cfg.RemoteSpecURL == "" {
+		panic(Error{Category: ErrConfig, Err: errors.New("NewAsyncAPI requires FilePath or RemoteSpecURL")})
+	}
+
+	return newHandler(cfg, map[string]*template.Template{
+		defaultIndex: mustParseTemplate("asyncapi_index.html", asyncAPITmpl),
+	})
+}
 
-	var (
-		prefix string
-		once   sync.Once
-	)
+// newHandler returns the handler serving the given pages and the spec document they render.
+func newHandler(cfg Config, pages map[string]*template.Template) fiber.Handler {
+	specs := newSpecStore(cfg.SpecCacheSize)
+	pagesGzip := newGzipCache(pageGzipCacheSize)
 
 	return func(c fiber.Ctx) error {
-		once.Do(func() {
-			prefix = strings.ReplaceAll(c.Route().Path, "*", "")
-			forwardedPrefix := getForwardedPrefix(c)
-			if forwardedPrefix != "" {
-				prefix = forwardedPrefix + prefix
-			}
+		if cfg.EchoRequestID {
+			echoRequestID(c)
+		}
 
-			if len(cfg.URL) == 0 {
-				cfg.URL = path.Join(prefix, defaultDocURL)
+		prefix := resolvePrefix(c, cfg)
+		trailingSlash := strings.HasSuffix(c.Path(), "/")
+		if cfg.InstanceNameParam != "" {
+			// Route parameters alias the request path, which is rewritten below.
+			c.Locals(instanceNameParamKey{}, strings.Clone(c.Params(strings.TrimPrefix(cfg.InstanceNameParam, ":"))))
+		}
+		p := c.Path(c.Params("*"))
+
+		if p == "" || p == "/" {
+			if !cfg.RedirectTrailingSlash {
+				c.Set("Location", joinPath(prefix, defaultIndex))
+				return c.Status(fiber.StatusMovedPermanently).Send(nil)
 			}
-		})
+			if !trailingSlash {
+				c.Set("Location", strings.TrimSuffix(joinPath(prefix), "/")+"/")
+				return c.Status(fiber.StatusMovedPermanently).Send(nil)
+			}
+			p = defaultIndex
+		}
 
-		p := c.Path(c.Params("*"))
+		if p == defaultDocURL {
+			return serveSpec(c, cfg, specs)
+		}
+
+		if cfg.NDJSONPathsEndpoint != "" && p == strings.TrimPrefix(cfg.NDJSONPathsEndpoint, "/") {
+			return servePathsNDJSON(c, cfg, specs)
+		}
+
+		if name, ok := strings.CutPrefix(p, componentSchemasPath); ok && cfg.ServeComponents {
+			return serveSchema(c, cfg, specs, name)
+		}
 
-		switch p {
-		case defaultIndex:
-			c.Type("html")
-			return index.Execute(c, cfg)
-		case defaultDocURL:
-			doc, err := swag.ReadDoc(cfg.InstanceName)
+		if p == swaggerConfigURL && cfg.ExternalizeConfig {
+			return c.JSON(renderConfig(c, cfg, prefix))
+		}
+
+		page, ok := pages[p]
+		if !ok {
+			return notFound(c, cfg)
+		}
+
+		render := renderConfig(c, cfg, prefix)
+		data := indexData{Config: render}
+		if _, ok := pages[oauth2RedirectIndex]; ok && render.OAuth2RedirectUrl == "" {
+			// Relative to the page and resolved in the browser, since the URL must be absolute
+			// and the external host and prefix are only known there.
+			data.OAuth2RedirectPath = "./" + oauth2RedirectIndex
+		}
+		if render.ExternalizeConfig {
+			// Relative to the page, so the page bytes do not depend on the prefix.
+			data.ConfigURL = "./" + swaggerConfigURL
+		}
+		if p == defaultIndex && render.InlineSpec && (render.SpecAuth == nil || render.SpecAuth(c)) {
+			doc, err := loadSpec(c, render, specs)
 			if err != nil {
 				return err
 			}
-			return c.Type("json").SendString(doc)
-		case "", "/":
-			c.Set("Location", path.Join(prefix, defaultIndex))
-			return c.Status(fiber.StatusMovedPermanently).Send(nil)
+			// Other documents, e.g. YAML, are not valid JavaScript and are left to the UI to fetch.
+			if doc.contentType == fiber.MIMEApplicationJSON {
+				data.Spec = inlineSpec(doc.body)
+				if render.InstanceNameFromCookie != "" {
+					c.Vary(fiber.HeaderCookie)
+				}
+			}
+		}
+
+		return renderIndex(c, page, data, pagesGzip)
+	}
+}
+
+// renderConfig returns the config the pages of the request are rendered with.
+func renderConfig(c fiber.Ctx, cfg Config, prefix string) Config {
+	render := localize(c, cfg)
+	if len(render.URL) == 0 {
+		render.URL = joinPath(prefix, defaultDocURL)
+	}
+	if cfg.BeforeRender != nil {
+		render = render.clone()
+		cfg.BeforeRender(c, &render)
+	}
+	if len(render.SpecURLQuery) > 0 {
+		render = withSpecURLQuery(render)
+	}
+	return render
+}
+
+// SpecHandler returns a Fiber handler that serves only the spec document, without
+// any UI. It serves the document at whatever path it is mounted on, or at
+// "doc.json" below a wildcard route, and responds with 404 to every other path.
+//
+// Usage:
+//
+//	app := fiber.New()
+//	app.Get("/openapi.json", swagger.SpecHandler())
+func SpecHandler(config ...Config) fiber.Handler {
+	cfg := configDefault(config...)
+	specs := newSpecStore(cfg.SpecCacheSize)
+
+	return func(c fiber.Ctx) error {
+		if cfg.EchoRequestID {
+			echoRequestID(c)
+		}
+
+		switch c.Params("*") {
+		case "", defaultDocURL:
+			return serveSpec(c, cfg, specs)
 		default:
-			return c.SendStatus(fiber.StatusNotFound)
+			return notFound(c, cfg)
 		}
 	}
 }
 
+// serveSpec authorizes the request and serves the spec document selected for it.
+func serveSpec(c fiber.Ctx, cfg Config, specs *specStore) error {
+	if cfg.SpecAuth != nil && !cfg.SpecAuth(c) {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if cfg.LocalizeSpecInfo {
+		cfg = localize(c, cfg)
+	}
+	doc, err := loadSpec(c, cfg, specs)
+	if err != nil {
+		return err
+	}
+
+	if callback := c.Query("callback"); cfg.AllowJSONP && callback != "" {
+		if !jsonpCallbackPattern.MatchString(callback) {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		// Other documents, e.g. YAML, cannot be wrapped into valid JavaScript.
+		if doc.contentType != fiber.MIMEApplicationJSON {
+			return c.SendStatus(fiber.StatusNotAcceptable)
+		}
+		body := make([]byte, 0, len(callback)+len(doc.body)+8)
+		body = append(body, "/**/"+callback+"("...)
+		body = append(body, doc.body...)
+		body = append(body, ");"...)
+
+		doc = newSpecDoc(body, doc.modTime)
+		doc.contentType = fiber.MIMEApplicationJavaScript
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	}
+	return sendSpec(c, cfg, doc)
+}
+
+// loadSpec returns the spec document selected for the request, with the
+// per-request transformation applied.
+func loadSpec(c fiber.Ctx, cfg Config, specs *specStore) (*specDoc, error) {
+	doc, err := specs.load(cfg, instanceName(c, cfg))
+	if err != nil {
+		return nil, Error{Category: ErrSpecRead, Err: err}
+	}
+	if cfg.OnReady != nil {
+		specs.ready.Do(func() {
+			cfg.OnReady(cfg)
+		})
+	}
+
+	if cfg.SpecTransformPerRequest != nil {
+		// Hand out a copy so the transform cannot corrupt the cached document.
+		body, err := cfg.SpecTransformPerRequest(c, append([]byte(nil), doc.body...))
+		if err != nil {
+			return nil, Error{Category: ErrSpecRead, Err: err}
+		}
+		transformed := newSpecDoc(body, doc.modTime)
+		transformed.contentType = doc.contentType
+		doc = transformed
+	}
+	return doc, nil
+}
+
+// notFound handles requests for unknown docs paths.
+func notFound(c fiber.Ctx, cfg Config) error {
+	if cfg.NotFoundHandler != nil {
+		return cfg.NotFoundHandler(c)
+	}
+	return c.SendStatus(fiber.StatusNotFound)
+}
+
+// echoRequestID copies the incoming "X-Request-ID" header onto the response,
+// generating a new ID when the request has none.
+func echoRequestID(c fiber.Ctx) {
+	id := c.Get(fiber.HeaderXRequestID)
+	if id == "" {
+		id = uuid.NewString()
+	}
+	c.Set(fiber.HeaderXRequestID, id)
+}
+
+// resolvePrefix returns the path the handler is mounted under for the current request,
+// including Config.ExternalPrefix or else the forwarded prefix set by a proxy.
+// It is taken from the request path rather than the route, so parameter segments
+// such as ":name" resolve to their values. Call it before c.Path is rewritten;
+// the result is copied since Fiber reuses the request path buffer.
+func resolvePrefix(c fiber.Ctx, cfg Config) string {
+	prefix := strings.Clone(strings.TrimSuffix(c.Path(), c.Params("*")))
+	if cfg.ExternalPrefix != "" {
+		return strings.TrimRight(cfg.ExternalPrefix, "/") + prefix
+	}
+	if forwardedPrefix := getForwardedPrefix(c); forwardedPrefix != "" {
+		prefix = forwardedPrefix + prefix
+	}
+	return prefix
+}
+
 // getForwardedPrefix extracts the "X-Forwarded-Prefix" header value from the request
 // and normalizes it by removing any trailing slashes. This prefix is useful when
 // the application is served behind a proxy or load balancer that modifies the route path.
@@ -95,3 +326,290 @@ func getForwardedPrefix(c fiber.Ctx) string {
 	}
 	return header[:endIndex]
 }
+
+// withSpecURLQuery returns cfg with Config.SpecURLQuery appended to the spec URLs.
+func withSpecURLQuery(cfg Config) Config {
+	query := make(url.Values, len(cfg.SpecURLQuery))
+	for key, value := range cfg.SpecURLQuery {
+		query.Set(key, value)
+	}
+	encoded := query.Encode()
+
+	appendQuery := func(u string) string {
+		if strings.Contains(u, "?") {
+			return u + "&" + encoded
+		}
+		return u + "?" + encoded
+	}
+
+	cfg.URL = appendQuery(cfg.URL)
+	if cfg.URLs != nil {
+		// Copy the entries, the slice is shared with other requests.
+		urls := make([]SpecURL, len(cfg.URLs))
+		for i, u := range cfg.URLs {
+			u.URL = appendQuery(u.URL)
+			urls[i] = u
+		}
+		cfg.URLs = urls
+	}
+	return cfg
+}
+
+// inlineSpec prepares a JSON spec document for embedding into an inline script.
+// HTML-sensitive characters are escaped, so the document cannot close the script element.
+func inlineSpec(body []byte) template.JS {
+	var buf bytes.Buffer
+	json.HTMLEscape(&buf, body)
+	return template.JS(buf.String())
+}
+
+// mustParseTemplate parses a page template and panics if it is invalid.
+func mustParseTemplate(name, text string) *template.Template {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		panic(Error{Category: ErrTemplate, Err: err})
+	}
+	return tmpl
+}
+
+// indexData is the data the page templates are executed with.
+type indexData struct {
+	Config
+
+	// Spec is the spec document embedded into the page when Config.InlineSpec is enabled.
+	Spec template.JS `json:"-"`
+
+	// OAuth2RedirectPath is the relative URL of the served OAuth2 redirect page, used when
+	// Config.OAuth2RedirectUrl is not set.
+	OAuth2RedirectPath string `json:"-"`
+
+	// ConfigURL is the URL Swagger UI loads its settings from when Config.ExternalizeConfig is enabled.
+	ConfigURL string `json:"-"`
+}
+
+// renderIndex executes the page template with the given data and writes the resulting page,
+// taking its gzip encoding from pagesGzip.
+func renderIndex(c fiber.Ctx, index *template.Template, data indexData, pagesGzip *gzipCache) error {
+	var buf bytes.Buffer
+	if err := index.Execute(&buf, data); err != nil {
+		return Error{Category: ErrTemplate, Err: err}
+	}
+
+	body := buf.Bytes()
+	if data.MinifyHTML {
+		body = minifyHTML(body)
+	}
+
+	c.Type("html")
+	varyEncoding(c, data.Config)
+	return sendNegotiated(c, shouldGzip(c, data.Config, len(body)), body, func() []byte { return pagesGzip.gzip(body) })
+}
+
+// jsonpCallbackPattern matches the JSONP callback names accepted by Config.AllowJSONP:
+// plain or dotted JavaScript identifiers, which cannot inject other code.
+var jsonpCallbackPattern = regexp.MustCompile(`^[A-Za-z_$][0-9A-Za-z_$]*(\.[A-Za-z_$][0-9A-Za-z_$]*)*$`)
+
+// protectedSpecHeaders lists the headers Config.SpecHeaders cannot override,
+// since they describe the encoding and framing of the served body.
+var protectedSpecHeaders = map[string]bool{
+	fiber.HeaderContentEncoding:  true,
+	fiber.HeaderContentLength:    true,
+	fiber.HeaderContentRange:     true,
+	fiber.HeaderContentType:      true,
+	fiber.HeaderTransferEncoding: true,
+}
+
+// serveSchema writes the named component schema of the spec document, or a 404
+// response when the document has no such schema.
+func serveSchema(c fiber.Ctx, cfg Config, specs *specStore, name string) error {
+	if cfg.SpecAuth != nil && !cfg.SpecAuth(c) {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	doc, err := loadSpec(c, cfg, specs)
+	if err != nil {
+		return err
+	}
+
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	schema, ok := doc.schema(name)
+	if !ok {
+		return notFound(c, cfg)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(schema)
+}
+
+// servePathsNDJSON writes the entries of the paths object of the spec document
+// as newline-delimited JSON.
+func servePathsNDJSON(c fiber.Ctx, cfg Config, specs *specStore) error {
+	if cfg.SpecAuth != nil && !cfg.SpecAuth(c) {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	doc, err := loadSpec(c, cfg, specs)
+	if err != nil {
+		return err
+	}
+
+	body, err := doc.pathsNDJSON()
+	if err != nil {
+		return Error{Category: ErrSpecRead, Err: err}
+	}
+
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	return c.Send(body)
+}
+
+// sendSpec writes the spec document with its cache validators and an explicit
+// Content-Length, so clients can track the download progress. Conditional
+// requests matching the current document are answered with 304 Not Modified,
+// and single byte ranges with 206 Partial Content. Full responses are
+// gzip-encoded when the client accepts it.
+func sendSpec(c fiber.Ctx, cfg Config, doc *specDoc) error {
+	etag := doc.etag
+	if cfg.DisableBuiltinETag {
+		etag = ""
+	} else {
+		c.Set(fiber.HeaderETag, etag)
+	}
+	if !doc.modTime.IsZero() {
+		c.Set(fiber.HeaderLastModified, doc.modTime.UTC().Format(http.TimeFormat))
+	}
+	if cfg.SpecVersionHeader != "" {
+		if version := doc.infoVersion(); version != "" {
+			c.Set(cfg.SpecVersionHeader, version)
+		}
+	}
+	for key, value := range cfg.SpecHeaders {
+		if !protectedSpecHeaders[http.CanonicalHeaderKey(key)] {
+			c.Set(key, value)
+		}
+	}
+
+	varyEncoding(c, cfg)
+	if cfg.InstanceNameFromCookie != "" {
+		// The cookie selects the document, so shared caches must key on it.
+		c.Vary(fiber.HeaderCookie)
+	}
+	if notModified(c, doc, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, doc.contentType)
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+
+	body := doc.body
+	if c.Get(fiber.HeaderRange) != "" && matchesIfRange(c, doc, etag) {
+		r, err := specRange(c, len(body))
+		switch {
+		case errors.Is(err, fiber.ErrRangeUnsatisfiable):
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", len(body)))
+			return c.SendStatus(fiber.StatusRequestedRangeNotSatisfiable)
+		case err == nil && r.Type == "bytes" && len(r.Ranges) == 1:
+			// Malformed and multipart ranges are answered with the full document.
+			// Ranges address the identity encoding, so partial content is never compressed.
+			start, end := r.Ranges[0].Start, r.Ranges[0].End
+			c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, len(body)))
+			c.Set(fiber.HeaderContentLength, strconv.Itoa(end-start+1))
+			return c.Status(fiber.StatusPartialContent).Send(body[start : end+1])
+		}
+	}
+
+	compress := shouldGzip(c, cfg, len(body))
+	if compress && etag != "" {
+		// The gzip encoding is a different representation, so it only carries a weak validator.
+		c.Set(fiber.HeaderETag, "W/"+etag)
+	}
+	return sendNegotiated(c, compress, body, doc.gzip)
+}
+
+// specRange parses the Range header of c for a document of size bytes. Unlike c.Range,
+// a suffix range longer than the document selects the whole document instead of being unsatisfiable.
+func specRange(c fiber.Ctx, size int) (fiber.Range, error) {
+	if suffix, ok := strings.CutPrefix(c.Get(fiber.HeaderRange), "bytes=-"); ok && size > 0 {
+		if n, err := strconv.Atoi(suffix); err == nil && n >= size {
+			r := fiber.Range{Type: "bytes"}
+			r.Ranges = append(r.Ranges, struct {
+				Start int
+				End   int
+			}{Start: 0, End: size - 1})
+			return r, nil
+		}
+	}
+	return c.Range(size)
+}
+
+// matchesIfRange reports whether a Range request applies to the current document,
+// i.e. it has no If-Range precondition or the precondition names the current validator.
+// etag is empty when Config.DisableBuiltinETag is set.
+func matchesIfRange(c fiber.Ctx, doc *specDoc, etag string) bool {
+	ifRange := c.Get(fiber.HeaderIfRange)
+	if ifRange == "" || ifRange == etag {
+		return true
+	}
+	if doc.modTime.IsZero() {
+		return false
+	}
+	date, err := http.ParseTime(ifRange)
+	return err == nil && !doc.modTime.Truncate(time.Second).After(date)
+}
+
+// notModified reports whether the client's cached copy of the document is still current.
+// If-None-Match takes precedence over If-Modified-Since. etag is empty when
+// Config.DisableBuiltinETag is set, so only a wildcard matches.
+func notModified(c fiber.Ctx, doc *specDoc, etag string) bool {
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, candidate := range strings.Split(noneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || (etag != "" && candidate == etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if doc.modTime.IsZero() {
+		return false
+	}
+	modifiedSince, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	return !doc.modTime.Truncate(time.Second).After(modifiedSince)
+}
+
+// instanceNameParamKey is the Locals key of the Config.InstanceNameParam value
+// captured before the handler rewrites the request path.
+type instanceNameParamKey struct{}
+
+// instanceName resolves the swag instance whose spec is served for the request.
+// A name taken from the request is only used when such an instance is registered,
+// otherwise the configured InstanceName is used.
+func instanceName(c fiber.Ctx, cfg Config) string {
+	if cfg.InstanceNameParam != "" {
+		name, ok := c.Locals(instanceNameParamKey{}).(string)
+		if !ok {
+			name = c.Params(strings.TrimPrefix(cfg.InstanceNameParam, ":"))
+		}
+		if name != "" && swag.GetSwagger(name) != nil {
+			return name
+		}
+	}
+	if cfg.InstanceNameFromCookie != "" {
+		if name := c.Cookies(cfg.InstanceNameFromCookie); name != "" && swag.GetSwagger(name) != nil {
+			return name
+		}
+	}
+	return cfg.InstanceName
+}
+
+// joinPath joins the given elements into a single clean, absolute URL path.
+// Empty elements, repeated slashes and trailing slashes are collapsed, so a
+// prefix such as "", "/" or "/api//" always yields a well-formed path.
+func joinPath(elem ...string) string {
+	return path.Join(append([]string{"/"}, elem...)...)
+}
@@ -1,11 +1,26 @@
 package swagger
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/compress"
+	"github.com/gofiber/fiber/v3/middleware/etag"
+	"github.com/google/uuid"
 	"github.com/swaggo/swag"
 )
 
@@ -54,6 +69,7 @@ func Test_Swagger(t *testing.T) {
 		statusCode  int
 		contentType string
 		location    string
+		length      bool
 	}{
 		{
 			name:        "Should be returns status 200 with 'text/html' content-type",
@@ -61,11 +77,24 @@ func Test_Swagger(t *testing.T) {
 			statusCode:  200,
 			contentType: "text/html",
 		},
+		{
+			name:        "Should be returns status 200 with 'text/html' content-type for ReDoc",
+			url:         "/swag/redoc.html",
+			statusCode:  200,
+			contentType: "text/html",
+		},
+		{
+			name:        "Should be returns status 200 with 'text/html' content-type for RapiDoc",
+			url:         "/swag/rapidoc.html",
+			statusCode:  200,
+			contentType: "text/html",
+		},
 		{
 			name:        "Should be returns status 200 with 'application/json' content-type",
 			url:         "/swag/doc.json",
 			statusCode:  200,
 			contentType: "application/json",
+			length:      true,
 		},
 		{
 			name:        "Should be returns status 200 with 'image/png' content-type",
@@ -109,6 +138,16 @@ func Test_Swagger(t *testing.T) {
 				}
 			}
 
+			if tt.length {
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.ContentLength != int64(len(body)) {
+					t.Fatalf(`Content-Length: got %d - expected %d`, resp.ContentLength, len(body))
+				}
+			}
+
 			if tt.location != "" {
 				location := resp.Header.Get("Location")
 				if location != tt.location {
@@ -157,3 +196,3484 @@ func Test_Swagger_Proxy_Redirect(t *testing.T) {
 		}
 	})
 }
+
+func Test_joinPath(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		expected string
+	}{
+		{prefix: "", expected: "/doc.json"},
+		{prefix: "/", expected: "/doc.json"},
+		{prefix: "/api//", expected: "/api/doc.json"},
+		{prefix: "/api//docs/", expected: "/api/docs/doc.json"},
+		{prefix: "api/docs", expected: "/api/docs/doc.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := joinPath(tt.prefix, defaultDocURL); got != tt.expected {
+				t.Fatalf(`URL: got %s - expected %s`, got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_Swagger_Messy_Prefix(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/api//docs/*", New())
+
+	req, err := http.NewRequest(http.MethodGet, "/api//docs/index.html", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusOK)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(body), `"url":"/api/docs/doc.json"`) {
+		t.Fatalf(`Body: expected spec URL "/api/docs/doc.json" in %s`, body)
+	}
+}
+
+func Test_Swagger_BeforeRender(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New(Config{
+		BeforeRender: func(c fiber.Ctx, cfg *Config) {
+			if theme := c.Cookies("theme"); theme != "" {
+				cfg.SyntaxHighlight.Theme = theme
+			}
+		},
+	}))
+
+	tests := []struct {
+		name   string
+		cookie string
+		theme  string
+	}{
+		{
+			name:   "Should render the theme from the cookie",
+			cookie: "monokai",
+			theme:  "monokai",
+		},
+		{
+			name:  "Should keep the default theme without a cookie",
+			theme: "agate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "theme", Value: tt.cookie})
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(body), `"theme":"`+tt.theme+`"`) {
+				t.Fatalf(`Body: expected theme %q in %s`, tt.theme, body)
+			}
+		})
+	}
+
+	if ConfigDefault.SyntaxHighlight.Theme != "agate" {
+		t.Fatalf(`ConfigDefault: got theme %s - expected agate`, ConfigDefault.SyntaxHighlight.Theme)
+	}
+}
+
+type staticSwag string
+
+func (s staticSwag) ReadDoc() string {
+	return string(s)
+}
+
+var registeredDocs sync.Map
+
+// registerDoc registers a static swag instance once per test binary.
+func registerDoc(name, doc string) {
+	if _, loaded := registeredDocs.LoadOrStore(name, struct{}{}); !loaded {
+		swag.Register(name, staticSwag(doc))
+	}
+}
+
+func Test_Swagger_InstanceNameFromCookie(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+	registerDoc("v2", `{"swagger":"2.0","info":{"title":"v2","version":"2.0"},"paths":{}}`)
+
+	app.Get("/swag/*", New(Config{
+		InstanceNameFromCookie: "apiver",
+	}))
+
+	tests := []struct {
+		name   string
+		cookie string
+		title  string
+	}{
+		{
+			name:   "Should serve the instance selected by the cookie",
+			cookie: "v2",
+			title:  `"title":"v2"`,
+		},
+		{
+			name:   "Should fall back to the default instance for unknown names",
+			cookie: "v3",
+			title:  `"title": "Swagger Example API"`,
+		},
+		{
+			name:  "Should serve the default instance without a cookie",
+			title: `"title": "Swagger Example API"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "apiver", Value: tt.cookie})
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusOK)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(body), tt.title) {
+				t.Fatalf(`Body: expected %s in %s`, tt.title, body)
+			}
+
+			if vary := resp.Header.Get(fiber.HeaderVary); !strings.Contains(vary, fiber.HeaderCookie) {
+				t.Fatalf(`Vary: got %s - expected it to include %s`, vary, fiber.HeaderCookie)
+			}
+		})
+	}
+}
+
+func Test_minifyHTML(t *testing.T) {
+	src := `
+<!DOCTYPE html>
+<html>
+  <head>
+    <!-- template comment -->
+    <title>  Swagger   UI </title>
+    <script>
+      const a = 1;
+      // keep me
+    </script>
+  </head>
+  <body>
+    <PRE>  keep
+  spacing</PRE>
+  </body>
+</html>
+`
+	expected := `<!DOCTYPE html> <html> <head> <title> Swagger UI </title> <script>
+      const a = 1;
+      // keep me
+    </script> </head> <body> <PRE>  keep
+  spacing</PRE> </body> </html>`
+
+	if got := string(minifyHTML([]byte(src))); got != expected {
+		t.Fatalf(`minifyHTML: got %q - expected %q`, got, expected)
+	}
+
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name:     "Should keep the space between inline elements",
+			src:      "<b>Docs</b>\n   <i>Home</i>",
+			expected: "<b>Docs</b> <i>Home</i>",
+		},
+		{
+			name:     "Should keep comment markers inside attribute values",
+			src:      `<meta content="a  <!-- b -->  c">  <!-- dropped -->`,
+			expected: `<meta content="a  <!-- b -->  c"> `,
+		},
+		{
+			name:     "Should keep '>' inside attribute values",
+			src:      `<a title='x > y'>  link  </a>`,
+			expected: `<a title='x > y'> link </a>`,
+		},
+		{
+			name:     "Should copy an unterminated comment through",
+			src:      "<p>text</p>  <!-- open <p>rest</p>",
+			expected: "<p>text</p> <!-- open <p>rest</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(minifyHTML([]byte(tt.src))); got != tt.expected {
+				t.Fatalf(`minifyHTML: got %q - expected %q`, got, tt.expected)
+			}
+		})
+	}
+}
+
+func Test_Swagger_MinifyHTML(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	render := func(cfg Config) string {
+		app := fiber.New()
+		app.Get("/swag/*", New(cfg))
+
+		req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	full := render(Config{})
+	minified := render(Config{MinifyHTML: true})
+
+	if len(minified) >= len(full) {
+		t.Fatalf(`Body: got %d bytes - expected less than %d`, len(minified), len(full))
+	}
+
+	if !strings.Contains(minified, "const ui = SwaggerUIBundle(config);") {
+		t.Fatalf(`Body: expected inline script to be preserved in %s`, minified)
+	}
+}
+
+func Test_Swagger_SpecAuth(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New(Config{
+		SpecAuth: func(c fiber.Ctx) bool {
+			return c.Get(fiber.HeaderAuthorization) == "Bearer secret"
+		},
+	}))
+
+	tests := []struct {
+		name       string
+		url        string
+		token      string
+		statusCode int
+	}{
+		{
+			name:       "Should keep the index page public",
+			url:        "/swag/index.html",
+			statusCode: 200,
+		},
+		{
+			name:       "Should reject the spec without a token",
+			url:        "/swag/doc.json",
+			statusCode: 401,
+		},
+		{
+			name:       "Should reject the spec with a wrong token",
+			url:        "/swag/doc.json",
+			token:      "Bearer wrong",
+			statusCode: 401,
+		},
+		{
+			name:       "Should serve the spec with a valid token",
+			url:        "/swag/doc.json",
+			token:      "Bearer secret",
+			statusCode: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.token != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.token)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, tt.statusCode)
+			}
+		})
+	}
+}
+
+type mutableSwag struct {
+	mu  sync.Mutex
+	doc string
+}
+
+func (s *mutableSwag) ReadDoc() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.doc
+}
+
+func (s *mutableSwag) set(doc string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.doc = doc
+}
+
+func Test_Swagger_SpecModTimeFunc(t *testing.T) {
+	app := fiber.New()
+
+	regenerated := &mutableSwag{doc: `{"swagger":"2.0","info":{"title":"first","version":"1.0"},"paths":{}}`}
+	if _, loaded := registeredDocs.LoadOrStore("regenerated", struct{}{}); !loaded {
+		swag.Register("regenerated", regenerated)
+	}
+
+	var (
+		mu      sync.Mutex
+		modTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	)
+	app.Get("/swag/*", New(Config{
+		InstanceName: "regenerated",
+		SpecModTimeFunc: func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return modTime
+		},
+	}))
+
+	get := func(header, value string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := get("", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusOK)
+	}
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if etag == "" {
+		t.Fatal(`ETag: expected a value`)
+	}
+	lastModified := resp.Header.Get(fiber.HeaderLastModified)
+	if lastModified != modTime.Format(http.TimeFormat) {
+		t.Fatalf(`Last-Modified: got %s - expected %s`, lastModified, modTime.Format(http.TimeFormat))
+	}
+
+	if resp := get(fiber.HeaderIfNoneMatch, etag); resp.StatusCode != http.StatusNotModified {
+		t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusNotModified)
+	}
+	if resp := get(fiber.HeaderIfModifiedSince, lastModified); resp.StatusCode != http.StatusNotModified {
+		t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusNotModified)
+	}
+
+	// The cached document is kept until the modification time advances.
+	regenerated.set(`{"swagger":"2.0","info":{"title":"second","version":"1.0"},"paths":{}}`)
+	if resp := get(fiber.HeaderIfNoneMatch, etag); resp.StatusCode != http.StatusNotModified {
+		t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusNotModified)
+	}
+
+	mu.Lock()
+	modTime = modTime.Add(time.Hour)
+	mu.Unlock()
+
+	resp = get(fiber.HeaderIfNoneMatch, etag)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusOK)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"title":"second"`) {
+		t.Fatalf(`Body: expected regenerated document, got %s`, body)
+	}
+	if resp.Header.Get(fiber.HeaderETag) == etag {
+		t.Fatal(`ETag: expected a new value for the regenerated document`)
+	}
+}
+
+func Test_Swagger_SpecModTimeFunc_Nil(t *testing.T) {
+	app := fiber.New()
+
+	changing := &mutableSwag{doc: `{"swagger":"2.0","host":"first.example","info":{},"paths":{}}`}
+	if _, loaded := registeredDocs.LoadOrStore("changing", struct{}{}); !loaded {
+		swag.Register("changing", changing)
+	}
+
+	app.Get("/swag/*", New(Config{InstanceName: "changing", DocJSONIndent: "  "}))
+
+	get := func() (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(body)
+	}
+
+	resp, _ := get()
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if resp, _ := get(); resp.Header.Get(fiber.HeaderETag) != etag {
+		t.Fatal(`ETag: expected the same value for an unchanged document`)
+	}
+
+	// Changes to the swag document, e.g. to SwaggerInfo.Host, are served right away.
+	changing.set(`{"swagger":"2.0","host":"second.example","info":{},"paths":{}}`)
+	resp, body := get()
+	if !strings.Contains(body, `"host": "second.example"`) {
+		t.Fatalf(`Body: expected the changed document, got %s`, body)
+	}
+	if resp.Header.Get(fiber.HeaderETag) == etag {
+		t.Fatal(`ETag: expected a new value for the changed document`)
+	}
+}
+
+func Test_Swagger_Double_Registration(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	handler := New()
+	app.Get("/first/*", handler)
+	app.Get("/second/*", handler)
+
+	tests := []struct {
+		name     string
+		prefix   string
+		location string
+		url      string
+	}{
+		{
+			name:     "Should serve links for the first mount",
+			prefix:   "/first",
+			location: "/first/index.html",
+			url:      `"url":"/first/doc.json"`,
+		},
+		{
+			name:     "Should serve links for the second mount",
+			prefix:   "/second",
+			location: "/second/index.html",
+			url:      `"url":"/second/doc.json"`,
+		},
+	}
+
+	// Run twice so the first resolved mount never leaks into the other one.
+	for i := 0; i < 2; i++ {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req, err := http.NewRequest(http.MethodGet, tt.prefix+"/", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if location := resp.Header.Get("Location"); location != tt.location {
+					t.Fatalf(`Location: got %s - expected %s`, location, tt.location)
+				}
+
+				req, err = http.NewRequest(http.MethodGet, tt.location, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				resp, err = app.Test(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !strings.Contains(string(body), tt.url) {
+					t.Fatalf(`Body: expected %s in %s`, tt.url, body)
+				}
+			})
+		}
+	}
+}
+
+func Test_Swagger_SpecSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+
+	small := filepath.Join(dir, "small.json")
+	if err := os.WriteFile(small, []byte(`{"swagger":"2.0","info":{"title":"file","version":"1.0"},"paths":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	large := filepath.Join(dir, "large.json")
+	if err := os.WriteFile(large, []byte(`{"swagger":"2.0","info":{"title":"`+strings.Repeat("x", 2048)+`"},"paths":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+	}{
+		{
+			name:       "Should serve a spec file within the limit",
+			path:       small,
+			statusCode: 200,
+		},
+		{
+			name:       "Should reject a spec file over the limit",
+			path:       large,
+			statusCode: 413,
+		},
+		{
+			name:       "Should fail on a missing spec file",
+			path:       filepath.Join(dir, "missing.json"),
+			statusCode: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(Config{
+				FilePath:      tt.path,
+				SpecSizeLimit: 1024,
+			}))
+
+			req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, tt.statusCode)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(body), dir) {
+				t.Fatalf(`Body: the file path must not reach the client - got %s`, body)
+			}
+		})
+	}
+}
+
+func Test_SpecHandler(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/openapi.json", SpecHandler())
+	app.Get("/spec/*", SpecHandler())
+
+	tests := []struct {
+		name        string
+		url         string
+		statusCode  int
+		contentType string
+	}{
+		{
+			name:        "Should serve the spec at the mounted path",
+			url:         "/openapi.json",
+			statusCode:  200,
+			contentType: "application/json",
+		},
+		{
+			name:        "Should serve the spec below a wildcard route",
+			url:         "/spec/doc.json",
+			statusCode:  200,
+			contentType: "application/json",
+		},
+		{
+			name:       "Should not serve the UI",
+			url:        "/spec/index.html",
+			statusCode: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, tt.statusCode)
+			}
+
+			if tt.contentType != "" {
+				if ct := resp.Header.Get("Content-Type"); ct != tt.contentType {
+					t.Fatalf(`Content-Type: got %s - expected %s`, ct, tt.contentType)
+				}
+			}
+		})
+	}
+}
+
+func Test_Swagger_RedirectTrailingSlash(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	tests := []struct {
+		name       string
+		config     Config
+		url        string
+		statusCode int
+		location   string
+	}{
+		{
+			name:       "Should redirect the prefix without trailing slash to the index",
+			url:        "/swag",
+			statusCode: 301,
+			location:   "/swag/index.html",
+		},
+		{
+			name:       "Should redirect the prefix with trailing slash to the index",
+			url:        "/swag/",
+			statusCode: 301,
+			location:   "/swag/index.html",
+		},
+		{
+			name:       "Should redirect the prefix without trailing slash to the canonical form",
+			config:     Config{RedirectTrailingSlash: true},
+			url:        "/swag",
+			statusCode: 301,
+			location:   "/swag/",
+		},
+		{
+			name:       "Should serve the UI at the canonical form",
+			config:     Config{RedirectTrailingSlash: true},
+			url:        "/swag/",
+			statusCode: 200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(tt.config))
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, tt.statusCode)
+			}
+
+			if location := resp.Header.Get("Location"); location != tt.location {
+				t.Fatalf(`Location: got %s - expected %s`, location, tt.location)
+			}
+		})
+	}
+}
+
+func Test_Swagger_HTMLFragments(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New(Config{
+		HeadHTML: `<meta property="og:title" content="API docs">`,
+		BodyHTML: `<noscript>JavaScript is required</noscript>`,
+	}))
+
+	for _, page := range []string{defaultIndex, redocIndex, rapidocIndex} {
+		t.Run(page, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/swag/"+page, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			head, rest, ok := strings.Cut(string(body), "</head>")
+			if !ok {
+				t.Fatalf(`Body: expected a head element in %s`, body)
+			}
+			if !strings.Contains(head, `<meta property="og:title" content="API docs">`) {
+				t.Fatalf(`Body: expected head fragment in %s`, head)
+			}
+			if !strings.Contains(rest, `<noscript>JavaScript is required</noscript>`) {
+				t.Fatalf(`Body: expected body fragment in %s`, rest)
+			}
+		})
+	}
+}
+
+func Test_waitTimeout(t *testing.T) {
+	t.Run("Should return once done is closed", func(t *testing.T) {
+		done := make(chan struct{})
+		close(done)
+		if err := waitTimeout(done, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Should time out a hung read", func(t *testing.T) {
+		err := waitTimeout(make(chan struct{}), 10*time.Millisecond)
+		if !errors.Is(err, ErrSpecReadTimeout) {
+			t.Fatalf(`Error: got %v - expected %v`, err, ErrSpecReadTimeout)
+		}
+
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusGatewayTimeout {
+			t.Fatalf(`Error: got %v - expected status %d`, err, fiber.StatusGatewayTimeout)
+		}
+	})
+}
+
+func Test_specStore_load(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "doc.json")
+	if err := os.WriteFile(file, []byte(`{"swagger":"2.0","info":{"title":"file","version":"1.0"},"paths":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{FilePath: file, SpecReadTimeout: 50 * time.Millisecond, SpecSizeLimit: -1}
+
+	specs := newSpecStore(2)
+	pending := &specRefresh{done: make(chan struct{})}
+	specs.refreshes[specKey(cfg, swag.Name)] = pending
+	defer close(pending.done)
+
+	t.Run("Should bound concurrent requests sharing a hung read", func(t *testing.T) {
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := specs.load(cfg, swag.Name); !errors.Is(err, ErrSpecReadTimeout) {
+					t.Errorf(`Error: got %v - expected %v`, err, ErrSpecReadTimeout)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if elapsed := time.Since(start); elapsed > 4*cfg.SpecReadTimeout {
+			t.Fatalf(`Elapsed: got %v - expected the requests to time out together`, elapsed)
+		}
+		if specs.refreshes[specKey(cfg, swag.Name)] != pending {
+			t.Fatal(`Refreshes: expected the pending read to be reused`)
+		}
+	})
+
+	t.Run("Should not block other documents", func(t *testing.T) {
+		other := cfg
+		other.InfoOverride = &InfoOverride{Title: "other"}
+
+		doc, err := specs.load(other, swag.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(doc.body), `"title":"other"`) {
+			t.Fatalf(`Body: got %s - expected the overridden title`, doc.body)
+		}
+	})
+}
+
+func Test_specStore_modTime(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "doc.json")
+	if err := os.WriteFile(file, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{FilePath: file, SpecReadTimeout: 10 * time.Millisecond}
+
+	t.Run("Should stat the file", func(t *testing.T) {
+		specs := newSpecStore(1)
+
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modTime, err := specs.modTime(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !modTime.Equal(info.ModTime()) {
+			t.Fatalf(`ModTime: got %v - expected %v`, modTime, info.ModTime())
+		}
+	})
+
+	t.Run("Should share a hung stat", func(t *testing.T) {
+		specs := newSpecStore(1)
+		pending := &specStat{done: make(chan struct{})}
+		specs.stats[file] = pending
+
+		for i := 0; i < 3; i++ {
+			if _, err := specs.modTime(cfg); !errors.Is(err, ErrSpecReadTimeout) {
+				t.Fatalf(`Error: got %v - expected %v`, err, ErrSpecReadTimeout)
+			}
+			if specs.stats[file] != pending {
+				t.Fatal(`Stats: expected the pending stat to be reused`)
+			}
+		}
+
+		pending.modTime = time.Unix(1, 0)
+		close(pending.done)
+		modTime, err := specs.modTime(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !modTime.Equal(pending.modTime) {
+			t.Fatalf(`ModTime: got %v - expected %v`, modTime, pending.modTime)
+		}
+	})
+}
+
+func Test_Swagger_RemoteSpecURL(t *testing.T) {
+	var (
+		hits    atomic.Int32
+		failing atomic.Bool
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"swagger":"2.0","info":{"title":"remote","version":"1.0"},"paths":{}}`))
+	}))
+	defer upstream.Close()
+
+	get := func(app *fiber.App) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("Should cache the remote spec", func(t *testing.T) {
+		hits.Store(0)
+		failing.Store(false)
+
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{RemoteSpecURL: upstream.URL}))
+
+		for i := 0; i < 3; i++ {
+			if status, body := get(app); status != http.StatusOK || !strings.Contains(body, `"title":"remote"`) {
+				t.Fatalf(`Response: got %d %s - expected the remote spec`, status, body)
+			}
+		}
+		if hits.Load() != 1 {
+			t.Fatalf(`Upstream hits: got %d - expected 1`, hits.Load())
+		}
+	})
+
+	t.Run("Should serve the last good copy when the upstream fails", func(t *testing.T) {
+		hits.Store(0)
+		failing.Store(false)
+
+		clock := newFakeClock()
+		app := fiber.New()
+		app.Get("/swag/*", New(withClock(Config{RemoteSpecURL: upstream.URL, RemoteSpecTTL: time.Minute}, clock)))
+
+		if status, _ := get(app); status != http.StatusOK {
+			t.Fatalf(`StatusCode: got %v - expected %v`, status, http.StatusOK)
+		}
+
+		failing.Store(true)
+		clock.Advance(time.Minute)
+
+		if status, body := get(app); status != http.StatusOK || !strings.Contains(body, `"title":"remote"`) {
+			t.Fatalf(`Response: got %d %s - expected the cached spec`, status, body)
+		}
+		if hits.Load() != 2 {
+			t.Fatalf(`Upstream hits: got %d - expected 2`, hits.Load())
+		}
+	})
+
+	t.Run("Should serve the last good copy to concurrent requests when the upstream fails", func(t *testing.T) {
+		failing.Store(false)
+
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{RemoteSpecURL: upstream.URL, RemoteSpecTTL: time.Nanosecond}))
+
+		if status, _ := get(app); status != http.StatusOK {
+			t.Fatalf(`StatusCode: got %v - expected %v`, status, http.StatusOK)
+		}
+
+		failing.Store(true)
+		var wg sync.WaitGroup
+		for i := 0; i < 40; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/swag/doc.json", nil))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if resp.StatusCode != http.StatusOK {
+					t.Errorf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusOK)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("Should serve the fallback when the upstream fails", func(t *testing.T) {
+		failing.Store(true)
+
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{
+			RemoteSpecURL:      upstream.URL,
+			RemoteSpecFallback: `{"swagger":"2.0","info":{"title":"fallback","version":"1.0"},"paths":{}}`,
+		}))
+
+		if status, body := get(app); status != http.StatusOK || !strings.Contains(body, `"title":"fallback"`) {
+			t.Fatalf(`Response: got %d %s - expected the fallback spec`, status, body)
+		}
+	})
+
+	t.Run("Should fail without a cached copy or fallback", func(t *testing.T) {
+		failing.Store(true)
+
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{RemoteSpecURL: upstream.URL}))
+
+		status, body := get(app)
+		if status != http.StatusBadGateway {
+			t.Fatalf(`StatusCode: got %v - expected %v`, status, http.StatusBadGateway)
+		}
+		if strings.Contains(body, upstream.URL) {
+			t.Fatalf(`Body: the upstream URL must not reach the client - got %s`, body)
+		}
+	})
+
+	t.Run("Should not name an unreachable upstream", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{RemoteSpecURL: unreachable.URL + "/internal/openapi.json"}))
+
+		status, body := get(app)
+		if status != http.StatusBadGateway {
+			t.Fatalf(`StatusCode: got %v - expected %v`, status, http.StatusBadGateway)
+		}
+		if strings.Contains(body, unreachable.URL) {
+			t.Fatalf(`Body: the upstream URL must not reach the client - got %s`, body)
+		}
+	})
+}
+
+func Test_Swagger_Range(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New())
+
+	doc := (&mockedSwag{}).ReadDoc()
+
+	tests := []struct {
+		name         string
+		rangeHeader  string
+		statusCode   int
+		contentRange string
+		body         string
+	}{
+		{
+			name:       "Should serve the full document without a range",
+			statusCode: 200,
+			body:       doc,
+		},
+		{
+			name:         "Should serve the requested range",
+			rangeHeader:  "bytes=0-9",
+			statusCode:   206,
+			contentRange: fmt.Sprintf("bytes 0-9/%d", len(doc)),
+			body:         doc[:10],
+		},
+		{
+			name:         "Should serve a suffix range",
+			rangeHeader:  "bytes=-5",
+			statusCode:   206,
+			contentRange: fmt.Sprintf("bytes %d-%d/%d", len(doc)-5, len(doc)-1, len(doc)),
+			body:         doc[len(doc)-5:],
+		},
+		{
+			name:         "Should serve the full document for a suffix range longer than it",
+			rangeHeader:  "bytes=-100000",
+			statusCode:   206,
+			contentRange: fmt.Sprintf("bytes 0-%d/%d", len(doc)-1, len(doc)),
+			body:         doc,
+		},
+		{
+			name:         "Should reject an unsatisfiable range",
+			rangeHeader:  fmt.Sprintf("bytes=%d-", len(doc)+10),
+			statusCode:   416,
+			contentRange: fmt.Sprintf("bytes */%d", len(doc)),
+		},
+		{
+			name:        "Should serve the full document for multiple ranges",
+			rangeHeader: "bytes=0-1,4-5",
+			statusCode:  200,
+			body:        doc,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.rangeHeader != "" {
+				req.Header.Set(fiber.HeaderRange, tt.rangeHeader)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, tt.statusCode)
+			}
+
+			if acceptRanges := resp.Header.Get(fiber.HeaderAcceptRanges); tt.statusCode != 416 && acceptRanges != "bytes" {
+				t.Fatalf(`Accept-Ranges: got %s - expected bytes`, acceptRanges)
+			}
+
+			if contentRange := resp.Header.Get(fiber.HeaderContentRange); contentRange != tt.contentRange {
+				t.Fatalf(`Content-Range: got %s - expected %s`, contentRange, tt.contentRange)
+			}
+
+			if tt.body != "" {
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(body) != tt.body {
+					t.Fatalf(`Body: got %q - expected %q`, body, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func Test_Swagger_SwaggerUIVersion(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New(Config{SwaggerUIVersion: "5.11.0"}))
+
+	req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(body), "/swagger-ui/5.11.0/swagger-ui-bundle.js") {
+		t.Fatalf(`Body: expected pinned swagger-ui version in %s`, body)
+	}
+	if strings.Contains(string(body), "/swagger-ui/4.1.3/") {
+		t.Fatalf(`Body: expected no default swagger-ui version in %s`, body)
+	}
+
+	t.Run("Should panic on an invalid version", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`New: expected a panic for an invalid version`)
+			}
+		}()
+		New(Config{SwaggerUIVersion: "latest"})
+	})
+}
+
+func Test_Swagger_OnReady(t *testing.T) {
+	app := fiber.New()
+
+	var calls atomic.Int32
+	app.Get("/swag/*", New(Config{
+		InstanceName: "missing",
+		OnReady: func(Config) {
+			calls.Add(1)
+		},
+	}))
+
+	get := func() int {
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := get(); status != http.StatusInternalServerError {
+		t.Fatalf(`StatusCode: got %v - expected %v`, status, http.StatusInternalServerError)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf(`OnReady: got %d calls - expected 0 before a successful load`, calls.Load())
+	}
+
+	registerDoc("missing", `{"swagger":"2.0","info":{"title":"ready","version":"1.0"},"paths":{}}`)
+
+	for i := 0; i < 3; i++ {
+		if status := get(); status != http.StatusOK {
+			t.Fatalf(`StatusCode: got %v - expected %v`, status, http.StatusOK)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf(`OnReady: got %d calls - expected 1`, calls.Load())
+	}
+}
+
+func Test_normalizeJSON(t *testing.T) {
+	first, err := normalizeJSON([]byte(`{"paths":{"/b":{},"/a":{}},"info":{"version":"1.0","title":"<API>"},"swagger":"2.0","x-big":12345678901234567890,"tags":["z","a"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := normalizeJSON([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "<API>", "version": "1.0"},
+  "tags": ["z", "a"],
+  "x-big": 12345678901234567890,
+  "paths": {"/a": {}, "/b": {}}
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"info":{"title":"<API>","version":"1.0"},"paths":{"/a":{},"/b":{}},"swagger":"2.0","tags":["z","a"],"x-big":12345678901234567890}`
+	if string(first) != expected {
+		t.Fatalf(`normalizeJSON: got %s - expected %s`, first, expected)
+	}
+	if string(second) != expected {
+		t.Fatalf(`normalizeJSON: got %s - expected %s`, second, expected)
+	}
+
+	if _, err := normalizeJSON([]byte(`{invalid`)); err == nil {
+		t.Fatal(`normalizeJSON: expected an error for invalid JSON`)
+	}
+}
+
+func Test_Swagger_EchoRequestID(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New(Config{EchoRequestID: true}))
+
+	for _, url := range []string{"/swag/index.html", "/swag/doc.json", "/swag/"} {
+		t.Run(url, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set(fiber.HeaderXRequestID, "incoming-id")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id := resp.Header.Get(fiber.HeaderXRequestID); id != "incoming-id" {
+				t.Fatalf(`X-Request-ID: got %s - expected incoming-id`, id)
+			}
+
+			req.Header.Del(fiber.HeaderXRequestID)
+			resp, err = app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := uuid.Parse(resp.Header.Get(fiber.HeaderXRequestID)); err != nil {
+				t.Fatalf(`X-Request-ID: expected a generated UUID, got %v`, err)
+			}
+		})
+	}
+}
+
+func Test_Swagger_NotFoundHandler(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New(Config{
+		NotFoundHandler: func(c fiber.Ctx) error {
+			return c.Status(fiber.StatusNotFound).SendString("custom not found")
+		},
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/swag/notfound", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusNotFound)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "custom not found" {
+		t.Fatalf(`Body: got %s - expected custom not found`, body)
+	}
+}
+
+func Test_Swagger_AutoRefreshInterval(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		expected bool
+	}{
+		{
+			name: "Should not poll by default",
+		},
+		{
+			name:     "Should poll the spec at the configured interval",
+			interval: 5 * time.Second,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(Config{AutoRefreshInterval: tt.interval}))
+
+			req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := strings.Contains(string(body), "},  5000 );"); got != tt.expected {
+				t.Fatalf(`Body: got refresh script %v - expected %v in %s`, got, tt.expected, body)
+			}
+			// Responses without an ETag, e.g. with DisableBuiltinETag, are compared by content.
+			if got := strings.Contains(string(body), "spec !== specText"); got != tt.expected {
+				t.Fatalf(`Body: got content comparison %v - expected %v in %s`, got, tt.expected, body)
+			}
+		})
+	}
+}
+
+func Test_Swagger_SpecHeaders(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New(Config{
+		SpecHeaders: map[string]string{
+			"Vary":              "Accept-Encoding",
+			"Surrogate-Control": "max-age=3600",
+			"content-type":      "text/plain",
+		},
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"Vary":              "Accept-Encoding",
+		"Surrogate-Control": "max-age=3600",
+		"Content-Type":      "application/json",
+	}
+	for key, value := range expected {
+		if got := resp.Header.Get(key); got != value {
+			t.Fatalf(`%s: got %s - expected %s`, key, got, value)
+		}
+	}
+}
+
+func Test_Config_clone(t *testing.T) {
+	cfg := Config{
+		SpecHeaders:  map[string]string{"Surrogate-Control": "max-age=3600"},
+		SpecURLQuery: map[string]string{"token": "secret"},
+		URLs:         []SpecURL{{Name: "v1", URL: "v1/doc.json"}},
+	}
+
+	clone := cfg.clone()
+	clone.SpecHeaders["Surrogate-Control"] = "no-store"
+	clone.SpecURLQuery["token"] = "other"
+	clone.URLs[0].Name = "v2"
+
+	if got := cfg.SpecHeaders["Surrogate-Control"]; got != "max-age=3600" {
+		t.Fatalf(`SpecHeaders: got %s - expected the original to stay unchanged`, got)
+	}
+	if got := cfg.SpecURLQuery["token"]; got != "secret" {
+		t.Fatalf(`SpecURLQuery: got %s - expected the original to stay unchanged`, got)
+	}
+	if got := cfg.URLs[0].Name; got != "v1" {
+		t.Fatalf(`URLs: got %s - expected the original to stay unchanged`, got)
+	}
+}
+
+func Test_Swagger_Concurrency(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	var modTime atomic.Int64
+	handler := New(Config{
+		BeforeRender: func(c fiber.Ctx, cfg *Config) {
+			cfg.Title = c.Query("title", cfg.Title)
+			cfg.SyntaxHighlight.Theme = c.Query("theme", cfg.SyntaxHighlight.Theme)
+		},
+		SpecModTimeFunc: func() time.Time {
+			// Advance on every call so the cached document is reloaded concurrently.
+			return time.Unix(modTime.Add(1), 0)
+		},
+	})
+	app.Get("/first/*", handler)
+	app.Get("/second/*", handler)
+
+	urls := []string{
+		"/first/index.html?theme=monokai",
+		"/second/index.html?title=Second",
+		"/first/doc.json",
+		"/second/doc.json",
+		"/first/",
+		"/second/redoc.html",
+	}
+
+	const workers = 32
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				url := urls[(i+j)%len(urls)]
+				req, err := http.NewRequest(http.MethodGet, url, nil)
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				resp, err := app.Test(req, fiber.TestConfig{Timeout: 5 * time.Second})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusMovedPermanently {
+					errs <- fmt.Errorf("%s: unexpected status %d", url, resp.StatusCode)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if ConfigDefault.SyntaxHighlight.Theme != "agate" || ConfigDefault.Title != "Swagger UI" {
+		t.Fatal(`ConfigDefault: expected the shared defaults to be left untouched`)
+	}
+}
+
+func Test_Swagger_SpecTransformPerRequest(t *testing.T) {
+	app := fiber.New()
+
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app.Get("/swag/*", New(Config{
+		SpecTransformPerRequest: func(c fiber.Ctx, doc []byte) ([]byte, error) {
+			switch region := c.Get("X-Region"); region {
+			case "":
+				return doc, nil
+			case "invalid":
+				return nil, errors.New("unknown region")
+			default:
+				// Mutate the received buffer in place to make sure the cache is not shared.
+				copy(doc, `{"x-region":"`+region+`",`)
+				return doc, nil
+			}
+		},
+	}))
+
+	get := func(region string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Region", region)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	for _, region := range []string{"eu", "us"} {
+		if status, body := get(region); status != http.StatusOK || !strings.HasPrefix(body, `{"x-region":"`+region+`",`) {
+			t.Fatalf(`Response: got %d %s - expected region %s`, status, body, region)
+		}
+	}
+
+	if _, body := get(""); body != (&mockedSwag{}).ReadDoc() {
+		t.Fatalf(`Body: expected the untouched cached document, got %s`, body)
+	}
+
+	if status, _ := get("invalid"); status != http.StatusInternalServerError {
+		t.Fatalf(`StatusCode: got %v - expected %v`, status, http.StatusInternalServerError)
+	}
+}
+
+func Test_Swagger_AllowJSONP(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	doc := (&mockedSwag{}).ReadDoc()
+
+	yamlFile := filepath.Join(t.TempDir(), "doc.yaml")
+	if err := os.WriteFile(yamlFile, []byte("swagger: '2.0'\ninfo:\n  title: yaml\n  version: '1.0'\npaths: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		config      Config
+		url         string
+		statusCode  int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "Should ignore the callback by default",
+			url:         "/swag/doc.json?callback=render",
+			statusCode:  200,
+			contentType: "application/json",
+			body:        doc,
+		},
+		{
+			name:        "Should serve JSONP with a callback",
+			config:      Config{AllowJSONP: true},
+			url:         "/swag/doc.json?callback=window.render",
+			statusCode:  200,
+			contentType: "application/javascript",
+			body:        "/**/window.render(" + doc + ");",
+		},
+		{
+			name:        "Should serve JSON without a callback",
+			config:      Config{AllowJSONP: true},
+			url:         "/swag/doc.json",
+			statusCode:  200,
+			contentType: "application/json",
+			body:        doc,
+		},
+		{
+			name:       "Should reject an unsafe callback",
+			config:     Config{AllowJSONP: true},
+			url:        "/swag/doc.json?callback=alert(1)//",
+			statusCode: 400,
+		},
+		{
+			name:       "Should not wrap a YAML document",
+			config:     Config{AllowJSONP: true, FilePath: yamlFile},
+			url:        "/swag/doc.json?callback=render",
+			statusCode: 406,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(tt.config))
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, tt.statusCode)
+			}
+
+			if tt.contentType != "" {
+				if ct := resp.Header.Get("Content-Type"); ct != tt.contentType {
+					t.Fatalf(`Content-Type: got %s - expected %s`, ct, tt.contentType)
+				}
+			}
+
+			if tt.body != "" {
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(body) != tt.body {
+					t.Fatalf(`Body: got %s - expected %s`, body, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func Test_Swagger_InlineSpec(t *testing.T) {
+	app := fiber.New()
+
+	registerDoc("inline", `{"swagger":"2.0","info":{"title":"</script><script>alert(1)</script>","version":"1.0"},"paths":{}}`)
+
+	app.Get("/swag/*", New(Config{
+		InstanceName: "inline",
+		InlineSpec:   true,
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusOK)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `config.spec = {"swagger":"2.0","info":{"title":"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e","version":"1.0"},"paths":{}};`
+	if !strings.Contains(string(body), expected) {
+		t.Fatalf(`Body: expected inline spec %s in %s`, expected, body)
+	}
+	if strings.Contains(string(body), "<script>alert(1)") {
+		t.Fatalf(`Body: expected the inline spec to be escaped in %s`, body)
+	}
+
+	yaml := filepath.Join(t.TempDir(), "openapi.yaml")
+	if err := os.WriteFile(yaml, []byte("openapi: 3.0.0\ninfo:\n  title: API\n  version: 1.0.0\npaths: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fallbacks := []struct {
+		name   string
+		config Config
+	}{
+		{
+			name:   "Should not inline a YAML document",
+			config: Config{FilePath: yaml, InlineSpec: true},
+		},
+		{
+			name: "Should not inline a document SpecAuth denies",
+			config: Config{
+				InstanceName: "inline",
+				InlineSpec:   true,
+				SpecAuth:     func(fiber.Ctx) bool { return false },
+			},
+		},
+	}
+
+	for _, tt := range fallbacks {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(tt.config))
+
+			req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(body), "config.spec") {
+				t.Fatalf(`Body: expected no inline spec in %s`, body)
+			}
+			if !strings.Contains(string(body), `"url":"/swag/doc.json"`) {
+				t.Fatalf(`Body: expected the spec URL in %s`, body)
+			}
+		})
+	}
+
+	t.Run("Should keep the spec URL for auto-refresh", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{InstanceName: "inline", InlineSpec: true, AutoRefreshInterval: time.Second}))
+
+		req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		specURL := strings.Index(string(body), "const specURL = config.url;")
+		deleted := strings.Index(string(body), "delete config.url;")
+		if specURL < 0 || deleted < specURL || !strings.Contains(string(body), "ui.specSelectors.url() || specURL") {
+			t.Fatalf(`Body: expected the spec URL to be kept before it is deleted in %s`, body)
+		}
+	})
+}
+
+func Test_Swagger_Error(t *testing.T) {
+	t.Run("Should categorize spec read failures", func(t *testing.T) {
+		app := fiber.New(fiber.Config{
+			ErrorHandler: func(c fiber.Ctx, err error) error {
+				var swaggerErr Error
+				if errors.As(err, &swaggerErr) && swaggerErr.Category == ErrSpecRead {
+					return c.SendStatus(fiber.StatusServiceUnavailable)
+				}
+				return fiber.DefaultErrorHandler(c, err)
+			},
+		})
+		app.Get("/swag/*", New(Config{InstanceName: "unregistered"}))
+
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusServiceUnavailable)
+		}
+	})
+
+	t.Run("Should keep the status of a wrapped fiber error", func(t *testing.T) {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer upstream.Close()
+
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{
+			RemoteSpecURL:   upstream.URL,
+			SpecReadTimeout: 20 * time.Millisecond,
+		}))
+
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusGatewayTimeout {
+			t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusGatewayTimeout)
+		}
+	})
+
+	t.Run("Should categorize config failures", func(t *testing.T) {
+		defer func() {
+			err, ok := recover().(error)
+			var swaggerErr Error
+			if !ok || !errors.As(err, &swaggerErr) || swaggerErr.Category != ErrConfig {
+				t.Fatalf(`New: got panic %v - expected a config error`, err)
+			}
+		}()
+		New(Config{SwaggerUIVersion: "v5"})
+	})
+}
+
+func Test_Swagger_InfoOverride(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	get := func(app *fiber.App, url, tenant string) string {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Tenant", tenant)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, http.StatusOK)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	t.Run("Should override the info of the served document", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{
+			InfoOverride: &InfoOverride{Title: "Acme API", Version: "2.0"},
+		}))
+
+		body := get(app, "/swag/doc.json", "")
+		for _, expected := range []string{`"title":"Acme API"`, `"version":"2.0"`, `"description":"This is a sample server."`, `"host":"petstore.swagger.io"`} {
+			if !strings.Contains(body, expected) {
+				t.Fatalf(`Body: expected %s in %s`, expected, body)
+			}
+		}
+
+		// The keys keep their document order.
+		var doc bytes.Buffer
+		if err := json.Compact(&doc, []byte((&mockedSwag{}).ReadDoc())); err != nil {
+			t.Fatal(err)
+		}
+		expected := strings.NewReplacer(`"title":"Swagger Example API"`, `"title":"Acme API"`, `"version":"1.0"`, `"version":"2.0"`).Replace(doc.String())
+		if body != expected {
+			t.Fatalf(`Body: got %s - expected %s`, body, expected)
+		}
+	})
+
+	t.Run("Should append a missing info object", func(t *testing.T) {
+		registerDoc("no-info", `{"swagger":"2.0","paths":{}}`)
+
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{
+			InstanceName: "no-info",
+			InfoOverride: &InfoOverride{Title: "Acme API", Version: "2.0"},
+		}))
+
+		if body, expected := get(app, "/swag/doc.json", ""), `{"swagger":"2.0","paths":{},"info":{"title":"Acme API","version":"2.0"}}`; body != expected {
+			t.Fatalf(`Body: got %s - expected %s`, body, expected)
+		}
+	})
+
+	t.Run("Should override the info per tenant", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{
+			InlineSpec: true,
+			BeforeRender: func(c fiber.Ctx, cfg *Config) {
+				cfg.InfoOverride = &InfoOverride{Title: c.Get("X-Tenant") + " API"}
+			},
+		}))
+
+		for _, tenant := range []string{"Acme", "Globex", "Acme"} {
+			body := get(app, "/swag/index.html", tenant)
+			if !strings.Contains(body, `"title":"`+tenant+` API"`) {
+				t.Fatalf(`Body: expected the %s title in %s`, tenant, body)
+			}
+		}
+	})
+}
+
+func Test_Swagger_UrlsPrimaryName(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	urls := []SpecURL{
+		{Name: "Service A", URL: "/a/doc.json"},
+		{Name: "Service B", URL: "/b/doc.json"},
+	}
+
+	tests := []struct {
+		name     string
+		primary  string
+		expected string
+	}{
+		{
+			name:     "Should select the first entry by default",
+			expected: `"urls.primaryName":"Service A"`,
+		},
+		{
+			name:     "Should select the configured entry",
+			primary:  "Service B",
+			expected: `"urls.primaryName":"Service B"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(Config{URLs: urls, UrlsPrimaryName: tt.primary}))
+
+			req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(body), `"urls":[{"name":"Service A","url":"/a/doc.json"},{"name":"Service B","url":"/b/doc.json"}]`) {
+				t.Fatalf(`Body: expected the URLs dropdown in %s`, body)
+			}
+			if !strings.Contains(string(body), tt.expected) {
+				t.Fatalf(`Body: expected %s in %s`, tt.expected, body)
+			}
+		})
+	}
+
+	t.Run("Should panic on an unknown primary name", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`New: expected a panic for an unknown primary name`)
+			}
+		}()
+		New(Config{URLs: urls, UrlsPrimaryName: "Service C"})
+	})
+}
+
+func Test_NewAsyncAPI(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "asyncapi.yaml")
+	if err := os.WriteFile(file, []byte("asyncapi: 2.6.0\ninfo:\n  title: Events\n  version: 1.0.0\nchannels: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/events/*", NewAsyncAPI(Config{FilePath: file}))
+
+	tests := []struct {
+		name        string
+		url         string
+		statusCode  int
+		contentType string
+		contains    string
+	}{
+		{
+			name:        "Should render the AsyncAPI page",
+			url:         "/events/index.html",
+			statusCode:  200,
+			contentType: "text/html",
+			contains:    "AsyncApiStandalone.render",
+		},
+		{
+			name:        "Should serve the document as YAML",
+			url:         "/events/doc.json",
+			statusCode:  200,
+			contentType: "application/yaml",
+			contains:    "asyncapi: 2.6.0",
+		},
+		{
+			name:       "Should not serve the Swagger UI pages",
+			url:        "/events/redoc.html",
+			statusCode: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, tt.statusCode)
+			}
+			if tt.statusCode != 200 {
+				return
+			}
+
+			if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+				t.Fatalf(`Content-Type: got %s - expected %s`, ct, tt.contentType)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(body), tt.contains) {
+				t.Fatalf(`Body: expected %s in %s`, tt.contains, body)
+			}
+		})
+	}
+
+	t.Run("Should panic without a document source", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`NewAsyncAPI: expected a panic without FilePath or RemoteSpecURL`)
+			}
+		}()
+		NewAsyncAPI()
+	})
+
+	t.Run("Should not modify the caller's config", func(t *testing.T) {
+		config := []Config{{FilePath: file}}
+		NewAsyncAPI(config...)
+
+		if config[0].Title != "" {
+			t.Fatalf(`Title: got %q - expected the caller's config to stay unset`, config[0].Title)
+		}
+	})
+}
+
+func Test_Swagger_RedactPaths(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "doc.json")
+	spec := `{"swagger":"2.0","info":{"title":"API","contact":{"email":"ops@internal"}},` +
+		`"paths":{"/login":{"post":{"parameters":[{"name":"token","example":"s3cr3t-token"}],` +
+		`"responses":{"200":{"description":"internal: hits the ledger","example":{"key":"s3cr3t-key"}}}}}}}`
+	if err := os.WriteFile(file, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/swag/*", New(Config{
+		FilePath:    file,
+		RedactPaths: []string{"**.example", "info.contact"},
+		Sanitizer: func(doc []byte) ([]byte, error) {
+			return []byte(strings.ReplaceAll(string(doc), "internal: ", "")), nil
+		},
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf(`StatusCode: got %v - expected 200`, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, redacted := range []string{`"example"`, "s3cr3t", `"contact"`, "internal: "} {
+		if strings.Contains(string(body), redacted) {
+			t.Fatalf(`Body: expected %s to be removed from %s`, redacted, body)
+		}
+	}
+	for _, kept := range []string{`"name":"token"`, `"description":"hits the ledger"`, `"title":"API"`} {
+		if !strings.Contains(string(body), kept) {
+			t.Fatalf(`Body: expected %s in %s`, kept, body)
+		}
+	}
+
+	// The remaining keys keep their document order.
+	expected := `{"swagger":"2.0","info":{"title":"API"},"paths":{"/login":{"post":{"parameters":[{"name":"token"}],` +
+		`"responses":{"200":{"description":"hits the ledger"}}}}}}`
+	if string(body) != expected {
+		t.Fatalf(`Body: got %s - expected %s`, body, expected)
+	}
+
+	t.Run("Should fail the request when the sanitizer fails", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/swag/*", New(Config{
+			FilePath: file,
+			Sanitizer: func([]byte) ([]byte, error) {
+				return nil, errors.New("boom")
+			},
+		}))
+
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 500 {
+			t.Fatalf(`StatusCode: got %v - expected 500`, resp.StatusCode)
+		}
+	})
+}
+
+func Test_Swagger_HideExploreBar(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	tests := []struct {
+		name   string
+		hide   bool
+		urls   []SpecURL
+		hidden bool
+	}{
+		{
+			name: "Should keep the explore bar by default",
+		},
+		{
+			name:   "Should hide the explore bar",
+			hide:   true,
+			hidden: true,
+		},
+		{
+			name: "Should keep the URLs dropdown when hiding the explore bar",
+			hide: true,
+			urls: []SpecURL{
+				{Name: "v1", URL: "v1/doc.json"},
+				{Name: "v2", URL: "v2/doc.json"},
+			},
+			hidden: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(Config{HideExploreBar: tt.hide, URLs: tt.urls}))
+
+			req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if hidden := strings.Contains(string(body), ".topbar .download-url-input, .swagger-ui .topbar .download-url-button { display: none"); hidden != tt.hidden {
+				t.Fatalf(`Body: explore bar hidden %v - expected %v`, hidden, tt.hidden)
+			}
+			if strings.Contains(string(body), "download-url-wrapper") {
+				t.Fatalf(`Body: URLs dropdown must stay visible`)
+			}
+			if len(tt.urls) > 0 && !strings.Contains(string(body), `"name":"v2"`) {
+				t.Fatalf(`Body: missing URLs - got %s`, body)
+			}
+		})
+	}
+}
+
+func Test_gzipCache(t *testing.T) {
+	cache := newGzipCache(1)
+	page := []byte("<html>" + strings.Repeat("page ", 100) + "</html>")
+
+	first := cache.gzip(page)
+	if second := cache.gzip(append([]byte(nil), page...)); &second[0] != &first[0] {
+		t.Fatal(`Gzip: expected an identical page to be compressed once`)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, page) {
+		t.Fatalf(`Gzip: got %s - expected %s`, decoded, page)
+	}
+
+	cache.gzip([]byte("<html>other</html>"))
+	if again := cache.gzip(page); &again[0] == &first[0] {
+		t.Fatal(`Gzip: expected the least recently used page to be evicted`)
+	}
+}
+
+func Test_Swagger_Gzip(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app := fiber.New()
+	// The mocked document is smaller than the default CompressionMinSize.
+	app.Get("/swag/*", New(Config{CompressionMinSize: -1}))
+
+	tests := []struct {
+		name           string
+		url            string
+		acceptEncoding string
+		gzipped        bool
+	}{
+		{
+			name:           "Should compress the spec document",
+			url:            "/swag/doc.json",
+			acceptEncoding: "gzip, deflate, br",
+			gzipped:        true,
+		},
+		{
+			name:           "Should compress the index page",
+			url:            "/swag/index.html",
+			acceptEncoding: "gzip",
+			gzipped:        true,
+		},
+		{
+			name: "Should not compress without Accept-Encoding",
+			url:  "/swag/doc.json",
+		},
+		{
+			name:           "Should not compress when gzip is refused",
+			url:            "/swag/doc.json",
+			acceptEncoding: "gzip;q=0, br",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != 200 {
+				t.Fatalf(`StatusCode: got %v - expected 200`, resp.StatusCode)
+			}
+			if vary := resp.Header.Get("Vary"); vary != "Accept-Encoding" {
+				t.Fatalf(`Vary: got %q - expected "Accept-Encoding"`, vary)
+			}
+
+			var body io.Reader = resp.Body
+			if encoding := resp.Header.Get("Content-Encoding"); (encoding == "gzip") != tt.gzipped {
+				t.Fatalf(`Content-Encoding: got %q - expected gzip %v`, encoding, tt.gzipped)
+			}
+			if tt.gzipped {
+				zr, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = zr
+			}
+
+			decoded, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(decoded), "Swagger") {
+				t.Fatalf(`Body: unexpected content %s`, decoded)
+			}
+		})
+	}
+}
+
+func Test_Swagger_ExternalPrefix(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app := fiber.New()
+	app.Get("/docs/*", New(Config{ExternalPrefix: "/public/"}))
+
+	t.Run("Should prepend the prefix to the spec URL", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/docs/index.html", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Forwarded-Prefix", "/other")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), `"url":"/public/docs/doc.json"`) {
+			t.Fatalf(`Body: expected the external spec URL in %s`, body)
+		}
+	})
+
+	t.Run("Should prepend the prefix to redirects", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "/docs", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 301 {
+			t.Fatalf(`StatusCode: got %v - expected 301`, resp.StatusCode)
+		}
+		if location := resp.Header.Get("Location"); location != "/public/docs/index.html" {
+			t.Fatalf(`Location: got %s - expected /public/docs/index.html`, location)
+		}
+	})
+}
+
+func Test_Swagger_Localized(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app := fiber.New()
+	app.Get("/swag/*", New(Config{
+		Localized: map[string]LocalizedInfo{
+			"en": {Title: "Pet Store API", Description: "Manage your pets"},
+			"de": {Title: "Tierhandlung API", Description: "Verwalten Sie Ihre Haustiere"},
+		},
+		DefaultLocale:    "en",
+		LocalizeSpecInfo: true,
+	}))
+
+	tests := []struct {
+		name           string
+		url            string
+		acceptLanguage string
+		expected       string
+	}{
+		{
+			name:           "Should pick the page title of the preferred language",
+			url:            "/swag/index.html",
+			acceptLanguage: "fr;q=0.9, de-AT, en;q=0.5",
+			expected:       "<title>Tierhandlung API</title>",
+		},
+		{
+			name:           "Should fall back to the default locale",
+			url:            "/swag/index.html",
+			acceptLanguage: "fr",
+			expected:       "<title>Pet Store API</title>",
+		},
+		{
+			name:           "Should localize the spec info",
+			url:            "/swag/doc.json",
+			acceptLanguage: "de",
+			expected:       `"description":"Verwalten Sie Ihre Haustiere"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != 200 {
+				t.Fatalf(`StatusCode: got %v - expected 200`, resp.StatusCode)
+			}
+			if vary := resp.Header.Get("Vary"); !strings.Contains(vary, "Accept-Language") {
+				t.Fatalf(`Vary: got %q - expected Accept-Language`, vary)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(body), tt.expected) {
+				t.Fatalf(`Body: expected %s in %s`, tt.expected, body)
+			}
+		})
+	}
+}
+
+func Test_Swagger_InstanceNameParam(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+	registerDoc("billing", `{"swagger":"2.0","info":{"title":"billing","version":"1.0"},"paths":{}}`)
+
+	app := fiber.New()
+	app.Get("/services/:name/docs/*", New(Config{InstanceNameParam: "name"}))
+	app.Get("/:name/*", New(Config{InstanceNameParam: ":name"}))
+
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "Should serve the spec of the instance named by the parameter",
+			url:      "/services/billing/docs/doc.json",
+			expected: `"title":"billing"`,
+		},
+		{
+			name:     "Should serve the spec of the instance named by a leading parameter",
+			url:      "/billing/doc.json",
+			expected: `"title":"billing"`,
+		},
+		{
+			name:     "Should fall back to the default instance",
+			url:      "/services/unknown/docs/doc.json",
+			expected: `"title": "Swagger Example API"`,
+		},
+		{
+			name:     "Should resolve the parameter in the spec URL",
+			url:      "/services/billing/docs/index.html",
+			expected: `"url":"/services/billing/docs/doc.json"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != 200 {
+				t.Fatalf(`StatusCode: got %v - expected 200`, resp.StatusCode)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(body), tt.expected) {
+				t.Fatalf(`Body: expected %s in %s`, tt.expected, body)
+			}
+		})
+	}
+}
+
+func Test_Swagger_SpecURLQuery(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected []string
+	}{
+		{
+			name:     "Should append the escaped query to the spec URL",
+			config:   Config{SpecURLQuery: map[string]string{"token": "a b&c"}},
+			expected: []string{`"url":"/swag/doc.json?token=a+b%26c"`},
+		},
+		{
+			name: "Should append the query to every URLs entry",
+			config: Config{
+				URLs: []SpecURL{
+					{Name: "A", URL: "/a/doc.json"},
+					{Name: "B", URL: "/b/doc.json?v=2"},
+				},
+				SpecURLQuery: map[string]string{"token": "t"},
+			},
+			expected: []string{`"url":"/a/doc.json?token=t"`, `"url":"/b/doc.json?v=2\u0026token=t"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(tt.config))
+
+			req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(string(body), expected) {
+					t.Fatalf(`Body: expected %s in %s`, expected, body)
+				}
+			}
+		})
+	}
+}
+
+func Test_specStore_Eviction(t *testing.T) {
+	registerDoc("cache", `{"swagger":"2.0","info":{"title":"cache","version":"1.0"},"paths":{}}`)
+
+	var reads atomic.Int32
+	store := newSpecStore(2)
+	load := func(title string) *specDoc {
+		t.Helper()
+		doc, err := store.load(configDefault(Config{
+			InfoOverride: &InfoOverride{Title: title},
+			Sanitizer: func(doc []byte) ([]byte, error) {
+				reads.Add(1)
+				return doc, nil
+			},
+		}), "cache")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	expectReads := func(expected int32) {
+		t.Helper()
+		if got := reads.Load(); got != expected {
+			t.Fatalf(`Reads: got %d - expected %d`, got, expected)
+		}
+	}
+
+	a, b := load("A"), load("B")
+	if a == b || !strings.Contains(string(a.body), `"title":"A"`) || !strings.Contains(string(b.body), `"title":"B"`) {
+		t.Fatalf(`Body: expected separate variants, got %s and %s`, a.body, b.body)
+	}
+	load("A")
+	load("B")
+	expectReads(2)
+
+	// A is the least recently used variant and gets evicted.
+	load("C")
+	load("B")
+	expectReads(3)
+	load("A")
+	expectReads(4)
+
+	ClearCache()
+	load("A")
+	expectReads(5)
+}
+
+func Test_Swagger_FriendlyTryItErrors(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app := fiber.New()
+	app.Get("/swag/*", New(Config{
+		FriendlyTryItErrors: true,
+		ResponseInterceptor: "function(res) { return res; }",
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := strings.Index(string(body), "config.responseInterceptor = function(res) { return res; };")
+	friendly := strings.Index(string(body), "const responseInterceptor = config.responseInterceptor;")
+	if user < 0 || friendly < 0 || friendly < user {
+		t.Fatalf(`Body: expected the friendly interceptor composed after the user interceptor in %s`, body)
+	}
+}
+
+func Test_Swagger_ExternalizeConfig(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app := fiber.New()
+	app.Get("/swag/*", New(Config{ExternalizeConfig: true, DeepLinking: true}))
+
+	get := func(t *testing.T, url, forwardedPrefix string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Forwarded-Prefix", forwardedPrefix)
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(body)
+	}
+
+	t.Run("Should serve stable page bytes pointing at the config route", func(t *testing.T) {
+		_, first := get(t, "/swag/index.html", "/a")
+		_, second := get(t, "/swag/index.html", "/b")
+
+		if first != second {
+			t.Fatalf(`Body: expected identical pages, got %s and %s`, first, second)
+		}
+		if !strings.Contains(first, `const config = { configUrl: "./swagger-config.json" };`) {
+			t.Fatalf(`Body: expected the config URL in %s`, first)
+		}
+		if strings.Contains(first, "doc.json") {
+			t.Fatalf(`Body: expected no inline settings in %s`, first)
+		}
+	})
+
+	t.Run("Should serve the settings as JSON", func(t *testing.T) {
+		resp, body := get(t, "/swag/swagger-config.json", "/a")
+
+		if resp.StatusCode != 200 {
+			t.Fatalf(`StatusCode: got %v - expected 200`, resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Fatalf(`Content-Type: got %s - expected application/json`, ct)
+		}
+		for _, expected := range []string{`"url":"/a/swag/doc.json"`, `"deepLinking":true`} {
+			if !strings.Contains(body, expected) {
+				t.Fatalf(`Body: expected %s in %s`, expected, body)
+			}
+		}
+	})
+
+	t.Run("Should not serve the settings when disabled", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/swag/*", New())
+
+		req, err := http.NewRequest(http.MethodGet, "/swag/swagger-config.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 404 {
+			t.Fatalf(`StatusCode: got %v - expected 404`, resp.StatusCode)
+		}
+	})
+}
+
+func Test_ValidateSpec(t *testing.T) {
+	if err := ValidateSpec([]byte((&mockedSwag{}).ReadDoc())); err != nil {
+		t.Fatalf(`ValidateSpec: got %v for the mocked document`, err)
+	}
+
+	tests := []struct {
+		name  string
+		doc   string
+		valid bool
+	}{
+		{
+			name:  "Should accept a swagger 2.0 document",
+			doc:   `{"swagger":"2.0","info":{"title":"API","version":"1.0"},"paths":{}}`,
+			valid: true,
+		},
+		{
+			name:  "Should accept an openapi 3.0 document",
+			doc:   `{"openapi":"3.0.3","info":{"title":"API","version":"1.0"},"paths":{}}`,
+			valid: true,
+		},
+		{
+			name:  "Should accept an openapi 3.1 document with only webhooks",
+			doc:   `{"openapi":"3.1.0","info":{"title":"API","version":"1.0"},"webhooks":{}}`,
+			valid: true,
+		},
+		{
+			name: "Should reject malformed JSON",
+			doc:  `{"swagger":`,
+		},
+		{
+			name: "Should reject a document without a version",
+			doc:  `{"info":{"title":"API","version":"1.0"},"paths":{}}`,
+		},
+		{
+			name: "Should reject an unsupported version",
+			doc:  `{"swagger":"1.2","info":{"title":"API","version":"1.0"},"paths":{}}`,
+		},
+		{
+			name: "Should reject a document without info",
+			doc:  `{"swagger":"2.0","paths":{}}`,
+		},
+		{
+			name: "Should reject an info object without a title",
+			doc:  `{"swagger":"2.0","info":{"version":"1.0"},"paths":{}}`,
+		},
+		{
+			name: "Should reject an openapi 3.0 document without paths",
+			doc:  `{"openapi":"3.0.3","info":{"title":"API","version":"1.0"},"webhooks":{}}`,
+		},
+		{
+			name: "Should reject paths that are not an object",
+			doc:  `{"swagger":"2.0","info":{"title":"API","version":"1.0"},"paths":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSpec([]byte(tt.doc))
+			if tt.valid && err != nil {
+				t.Fatalf(`ValidateSpec: got %v - expected nil`, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidSpec) {
+				t.Fatalf(`ValidateSpec: got %v - expected ErrInvalidSpec`, err)
+			}
+		})
+	}
+}
+
+func Test_Swagger_CompressionMinSize(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.json")
+	if err := os.WriteFile(small, []byte(`{"swagger":"2.0","info":{"title":"small","version":"1.0"},"paths":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	large := filepath.Join(dir, "large.json")
+	if err := os.WriteFile(large, []byte(`{"swagger":"2.0","info":{"title":"large","version":"1.0","description":"`+strings.Repeat("x", 2048)+`"},"paths":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		encoding string
+	}{
+		{
+			name: "Should serve a small document uncompressed",
+			path: small,
+		},
+		{
+			name:     "Should compress a large document",
+			path:     large,
+			encoding: "gzip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(Config{FilePath: tt.path}))
+
+			req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept-Encoding", "gzip")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if encoding := resp.Header.Get("Content-Encoding"); encoding != tt.encoding {
+				t.Fatalf(`Content-Encoding: got %q - expected %q`, encoding, tt.encoding)
+			}
+		})
+	}
+}
+
+func Test_Swagger_DocJSONIndent(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "doc.json")
+	if err := os.WriteFile(file, []byte(`{"swagger":"2.0","info":{"title":"API","version":"1.0"},"paths":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Get("/swag/*", New(Config{FilePath: file, DocJSONIndent: "\t"}))
+
+	req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\n\t\"swagger\": \"2.0\",\n\t\"info\": {\n\t\t\"title\": \"API\",\n\t\t\"version\": \"1.0\"\n\t},\n\t\"paths\": {}\n}"
+	if string(body) != expected {
+		t.Fatalf(`Body: got %s - expected %s`, body, expected)
+	}
+
+	t.Run("Should panic on a non-whitespace indent", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`New: expected a panic for an invalid DocJSONIndent`)
+			}
+		}()
+		New(Config{DocJSONIndent: "--"})
+	})
+}
+
+func Test_Swagger_DisableBuiltin(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	t.Run("Should leave compression to the compress middleware", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(compress.New())
+		app.Get("/swag/*", New(Config{DisableBuiltinCompression: true, CompressionMinSize: -1}))
+
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if encoding := resp.Header.Get("Content-Encoding"); encoding != "gzip" {
+			t.Fatalf(`Content-Encoding: got %q - expected gzip`, encoding)
+		}
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ValidateSpec(body); err != nil {
+			t.Fatalf(`Body: expected the spec document once decoded, got %v`, err)
+		}
+	})
+
+	t.Run("Should leave validators to the etag middleware", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(etag.New())
+		app.Get("/swag/*", New(Config{DisableBuiltinETag: true}))
+
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tag := resp.Header.Values("ETag")
+		if len(tag) != 1 {
+			t.Fatalf(`ETag: got %v - expected the middleware's only`, tag)
+		}
+
+		req, err = http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("If-None-Match", tag[0])
+
+		resp, err = app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != 304 {
+			t.Fatalf(`StatusCode: got %v - expected 304`, resp.StatusCode)
+		}
+	})
+}
+
+func Test_Swagger_ServeComponents(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "openapi.json")
+	spec := `{"openapi":"3.0.3","info":{"title":"API","version":"1.0"},"paths":{},` +
+		`"components":{"schemas":{"User":{"type":"object","properties":{"id":{"type":"integer"}}}}}}`
+	if err := os.WriteFile(file, []byte(spec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	registerDoc("definitions", `{"swagger":"2.0","info":{"title":"API","version":"1.0"},"paths":{},"definitions":{"model.Pet":{"type":"object"}}}`)
+
+	app := fiber.New()
+	app.Get("/docs/*", New(Config{FilePath: file, ServeComponents: true}))
+	app.Get("/v2/*", New(Config{InstanceName: "definitions", ServeComponents: true}))
+	app.Get("/off/*", New(Config{FilePath: file}))
+
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "Should serve a component schema",
+			url:        "/docs/components/schemas/User",
+			statusCode: 200,
+			body:       `{"type":"object","properties":{"id":{"type":"integer"}}}`,
+		},
+		{
+			name:       "Should serve a swagger 2.0 definition",
+			url:        "/v2/components/schemas/model.Pet",
+			statusCode: 200,
+			body:       `{"type":"object"}`,
+		},
+		{
+			name:       "Should return 404 for an unknown schema",
+			url:        "/docs/components/schemas/Order",
+			statusCode: 404,
+		},
+		{
+			name:       "Should return 404 when disabled",
+			url:        "/off/components/schemas/User",
+			statusCode: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf(`StatusCode: got %v - expected %v`, resp.StatusCode, tt.statusCode)
+			}
+			if tt.statusCode != 200 {
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.body {
+				t.Fatalf(`Body: got %s - expected %s`, body, tt.body)
+			}
+		})
+	}
+}
+
+func Test_Swagger_Favicons(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected []string
+		absent   string
+	}{
+		{
+			name: "Should render the Swagger UI favicons by default",
+			expected: []string{
+				`<link rel="icon" type="image/png" href="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/4.1.3/favicon-32x32.png" sizes="32x32" />`,
+			},
+		},
+		{
+			name:     "Should render the favicon shortcut",
+			config:   Config{FaviconURL: "/static/favicon.ico"},
+			expected: []string{`<link rel="icon" href="/static/favicon.ico" />`},
+			absent:   "favicon-32x32.png",
+		},
+		{
+			name: "Should render every favicon link",
+			config: Config{
+				FaviconURL: "/static/favicon.ico",
+				Favicons: []FaviconLink{
+					{Sizes: "16x16", Type: "image/png", Href: "/static/icon-16.png"},
+					{Sizes: "32x32", Type: "image/png", Href: "/static/icon-32.png"},
+					{Rel: "apple-touch-icon", Sizes: "180x180", Href: "/static/apple-touch-icon.png"},
+				},
+			},
+			expected: []string{
+				`<link rel="icon" type="image/png" href="/static/icon-16.png" sizes="16x16" />`,
+				`<link rel="icon" type="image/png" href="/static/icon-32.png" sizes="32x32" />`,
+				`<link rel="apple-touch-icon" href="/static/apple-touch-icon.png" sizes="180x180" />`,
+			},
+			absent: "/static/favicon.ico",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/swag/*", New(tt.config))
+
+			req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(string(body), expected) {
+					t.Fatalf(`Body: expected %s in %s`, expected, body)
+				}
+			}
+			if tt.absent != "" && strings.Contains(string(body), tt.absent) {
+				t.Fatalf(`Body: expected no %s in %s`, tt.absent, body)
+			}
+		})
+	}
+}
+
+func Test_Swagger_SpecVersionHeader(t *testing.T) {
+	registerDoc("versioned", `{"swagger":"2.0","info":{"title":"API","version":"2.3.1"},"paths":{}}`)
+
+	app := fiber.New()
+	app.Add([]string{fiber.MethodGet, fiber.MethodHead}, "/docs/*", New(Config{InstanceName: "versioned", SpecVersionHeader: "X-API-Version"}))
+
+	req, err := http.NewRequest(http.MethodHead, "/docs/doc.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf(`StatusCode: got %v - expected 200`, resp.StatusCode)
+	}
+	if version := resp.Header.Get("X-API-Version"); version != "2.3.1" {
+		t.Fatalf(`X-API-Version: got %q - expected "2.3.1"`, version)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 0 {
+		t.Fatalf(`Body: expected no body for HEAD, got %s`, body)
+	}
+}
+
+func Test_Swagger_DeepPrefix(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app := fiber.New()
+	app.Get("/internal/tools/api/docs/*", New(Config{ExternalizeConfig: true}))
+
+	get := func(t *testing.T, url string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(body)
+	}
+
+	t.Run("Should redirect below the prefix", func(t *testing.T) {
+		resp, _ := get(t, "/internal/tools/api/docs")
+		if location := resp.Header.Get("Location"); location != "/internal/tools/api/docs/index.html" {
+			t.Fatalf(`Location: got %s - expected /internal/tools/api/docs/index.html`, location)
+		}
+	})
+
+	t.Run("Should resolve the page URLs next to the page", func(t *testing.T) {
+		_, body := get(t, "/internal/tools/api/docs/index.html")
+		for _, expected := range []string{
+			`configUrl: "./swagger-config.json"`,
+			`config.oauth2RedirectUrl = new URL("./oauth2-redirect.html", window.location.href).href;`,
+		} {
+			if !strings.Contains(body, expected) {
+				t.Fatalf(`Body: expected %s in %s`, expected, body)
+			}
+		}
+	})
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "/internal/tools/api/docs/swagger-config.json", expected: `"url":"/internal/tools/api/docs/doc.json"`},
+		{url: "/internal/tools/api/docs/doc.json", expected: `"swagger": "2.0"`},
+		{url: "/internal/tools/api/docs/oauth2-redirect.html", expected: "window.opener.swaggerUIRedirectOauth2"},
+	}
+
+	for _, tt := range tests {
+		t.Run("Should serve "+tt.url, func(t *testing.T) {
+			resp, body := get(t, tt.url)
+			if resp.StatusCode != 200 {
+				t.Fatalf(`StatusCode: got %v - expected 200`, resp.StatusCode)
+			}
+			if !strings.Contains(body, tt.expected) {
+				t.Fatalf(`Body: expected %s in %s`, tt.expected, body)
+			}
+		})
+	}
+
+	t.Run("Should keep a configured OAuth2 redirect URL", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/docs/*", New(Config{OAuth2RedirectUrl: "https://example.com/callback"}))
+
+		req, err := http.NewRequest(http.MethodGet, "/docs/index.html", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(body), "oauth2-redirect.html") {
+			t.Fatalf(`Body: expected the configured redirect URL only in %s`, body)
+		}
+	})
+}
+
+func Test_Swagger_PreInitScript(t *testing.T) {
+	registrationOnce.Do(func() {
+		swag.Register(swag.Name, &mockedSwag{})
+	})
+
+	app := fiber.New()
+	app.Get("/swag/*", New(Config{
+		PreInitScript:      "window.hostToken = 'abc';",
+		RequestInterceptor: "function(req) { req.headers.Authorization = window.hostToken; return req; }",
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/swag/index.html", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script := strings.Index(string(body), "window.hostToken = 'abc';")
+	bundle := strings.Index(string(body), "const ui = SwaggerUIBundle(config);")
+	interceptor := strings.Index(string(body), "config.requestInterceptor =")
+	if script < 0 || bundle < 0 || interceptor < 0 || script < interceptor || script > bundle {
+		t.Fatalf(`Body: expected the script right before SwaggerUIBundle in %s`, body)
+	}
+}
+
+func Test_Swagger_NDJSONPathsEndpoint(t *testing.T) {
+	registerDoc("ndjson", `{"swagger":"2.0","info":{"title":"API","version":"1.0"},"paths":{
+		"/users": {"get": {"summary": "List users"}},
+		"/orders": {"post": {"summary": "Create order"}}
+	}}`)
+
+	app := fiber.New()
+	app.Get("/docs/*", New(Config{InstanceName: "ndjson", NDJSONPathsEndpoint: "paths.ndjson"}))
+
+	req, err := http.NewRequest(http.MethodGet, "/docs/paths.ndjson", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != 200 {
+		t.Fatalf(`StatusCode: got %v - expected 200`, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf(`Content-Type: got %s - expected application/x-ndjson`, ct)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"path":"/users","item":{"get":{"summary":"List users"}}}` + "\n" +
+		`{"path":"/orders","item":{"post":{"summary":"Create order"}}}` + "\n"
+	if string(body) != expected {
+		t.Fatalf(`Body: got %s - expected %s`, body, expected)
+	}
+}
+
+// fakeClock is a time source for Config.now that only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// withClock returns cfg with clock as its time source. Config.now is unexported,
+// so in-package tests set it through this helper.
+func withClock(cfg Config, clock *fakeClock) Config {
+	cfg.now = clock.Now
+	return cfg
+}
+
+func Test_Swagger_RemoteSpecTTL_Clock(t *testing.T) {
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		_, _ = fmt.Fprintf(w, `{"swagger":"2.0","info":{"title":"remote","version":"%d"},"paths":{}}`, hits.Load())
+	}))
+	defer upstream.Close()
+
+	clock := newFakeClock()
+	app := fiber.New()
+	app.Get("/swag/*", New(withClock(Config{RemoteSpecURL: upstream.URL, RemoteSpecTTL: time.Minute}, clock)))
+
+	get := func(expectedVersion string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), `"version":"`+expectedVersion+`"`) {
+			t.Fatalf(`Body: expected version %s in %s`, expectedVersion, body)
+		}
+	}
+
+	get("1")
+	clock.Advance(59 * time.Second)
+	get("1")
+	if hits.Load() != 1 {
+		t.Fatalf(`Upstream hits: got %d - expected 1 within the TTL`, hits.Load())
+	}
+
+	clock.Advance(time.Second)
+	get("2")
+	if hits.Load() != 2 {
+		t.Fatalf(`Upstream hits: got %d - expected 2 once the TTL elapsed`, hits.Load())
+	}
+}
+
+func Test_Swagger_RemoteSpecURL_SlowRefresh(t *testing.T) {
+	var (
+		hits    atomic.Int32
+		blocked = make(chan struct{})
+		release = make(chan struct{})
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if hits.Add(1) > 1 {
+			close(blocked)
+			<-release
+		}
+		_, _ = fmt.Fprintf(w, `{"swagger":"2.0","info":{"title":"remote","version":"%d"},"paths":{}}`, hits.Load())
+	}))
+	defer upstream.Close()
+
+	clock := newFakeClock()
+	app := fiber.New()
+	app.Get("/swag/*", New(withClock(Config{RemoteSpecURL: upstream.URL, RemoteSpecTTL: time.Minute}, clock)))
+
+	get := func() string {
+		req, err := http.NewRequest(http.MethodGet, "/swag/doc.json", nil)
+		if err != nil {
+			t.Error(err)
+			return ""
+		}
+
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 5 * time.Second, FailOnTimeout: true})
+		if err != nil {
+			t.Error(err)
+			return ""
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		return string(body)
+	}
+
+	if body := get(); !strings.Contains(body, `"version":"1"`) {
+		t.Fatalf(`Body: expected version 1 in %s`, body)
+	}
+	clock.Advance(2 * time.Minute)
+
+	refreshed := make(chan string)
+	go func() { refreshed <- get() }()
+	<-blocked
+
+	// The refresh is stuck upstream, other requests get the expired copy meanwhile.
+	if body := get(); !strings.Contains(body, `"version":"1"`) {
+		t.Fatalf(`Body: expected the expired copy in %s`, body)
+	}
+	if hits.Load() != 2 {
+		t.Fatalf(`Upstream hits: got %d - expected a single refresh`, hits.Load())
+	}
+
+	close(release)
+	if body := <-refreshed; !strings.Contains(body, `"version":"2"`) {
+		t.Fatalf(`Body: expected the refreshed copy in %s`, body)
+	}
+}
@@ -0,0 +1,92 @@
+package swagger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSpec is returned by ValidateSpec for structurally invalid documents.
+var ErrInvalidSpec = errors.New("invalid spec document")
+
+// ValidateSpec checks that a JSON spec document parses and has the required
+// top-level fields for its version: "swagger": "2.0" with "info" and "paths",
+// or "openapi": "3.x" with "info" and "paths" ("paths", "components" or
+// "webhooks" since 3.1). The info object must carry a title and a version.
+// It does not validate the operations or schemas, and is meant for tests such as
+//
+//	doc, _ := swag.ReadDoc()
+//	if err := swagger.ValidateSpec([]byte(doc)); err != nil {
+//		t.Fatal(err)
+//	}
+func ValidateSpec(doc []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+
+	var swaggerVersion, openAPIVersion string
+	if err := unmarshalField(fields, "swagger", &swaggerVersion); err != nil {
+		return err
+	}
+	if err := unmarshalField(fields, "openapi", &openAPIVersion); err != nil {
+		return err
+	}
+
+	required := []string{"paths"}
+	switch {
+	case swaggerVersion == "2.0":
+	case strings.HasPrefix(openAPIVersion, "3.0."):
+	case strings.HasPrefix(openAPIVersion, "3."):
+		required = []string{"paths", "components", "webhooks"}
+	case swaggerVersion != "":
+		return fmt.Errorf("%w: unsupported swagger version %q", ErrInvalidSpec, swaggerVersion)
+	case openAPIVersion != "":
+		return fmt.Errorf("%w: unsupported openapi version %q", ErrInvalidSpec, openAPIVersion)
+	default:
+		return fmt.Errorf("%w: missing swagger or openapi version field", ErrInvalidSpec)
+	}
+
+	var info struct {
+		Title   *string `json:"title"`
+		Version *string `json:"version"`
+	}
+	if _, ok := fields["info"]; !ok {
+		return fmt.Errorf("%w: missing info object", ErrInvalidSpec)
+	}
+	if err := unmarshalField(fields, "info", &info); err != nil {
+		return err
+	}
+	if info.Title == nil || info.Version == nil {
+		return fmt.Errorf("%w: info object requires title and version", ErrInvalidSpec)
+	}
+
+	found := false
+	for _, name := range required {
+		var object map[string]json.RawMessage
+		if err := unmarshalField(fields, name, &object); err != nil {
+			return err
+		}
+		if _, ok := fields[name]; ok {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: missing %s", ErrInvalidSpec, strings.Join(required, " or "))
+	}
+	return nil
+}
+
+// unmarshalField decodes the named top-level field of a spec document into v,
+// leaving v untouched when the field is absent.
+func unmarshalField(fields map[string]json.RawMessage, name string, v interface{}) error {
+	raw, ok := fields[name]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w: field %q: %w", ErrInvalidSpec, name, err)
+	}
+	return nil
+}